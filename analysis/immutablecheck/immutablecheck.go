@@ -0,0 +1,355 @@
+// Package immutablecheck provides an analyzer which reports code modifying slices that
+// are meant to be immutable.
+//
+// A slice is considered immutable when it is:
+//
+//   - returned by a function of the immutableslice package or loaded from an
+//     immutableslice.Atomic, as such slices may be shared with other code,
+//   - a parameter named by an //immutable:readonly directive in the doc comment of its
+//     function, or any parameter of the function if the directive names none,
+//   - of a named type whose declaration carries an //immutable:readonly directive,
+//   - or a variable assigned, or a slice expression of, one of the above.
+//
+// The analyzer reports writes to elements of immutable slices, appending to them, which
+// may write into capacity shared with other slices, passing them as the destination of
+// clear and copy, and passing them to the mutating functions of the slices and sort
+// packages, suggesting the immutableslice equivalent.
+//
+// Variables are tracked in the order they appear in the source rather than along the
+// control flow, so assigning a fresh slice, e.g. from slices.Clone, to a variable makes
+// it mutable for all code following the assignment.
+package immutablecheck
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+const (
+	immutableslicePath = "github.com/mkeeler/go-immutable/immutableslice"
+	directive          = "//immutable:readonly"
+)
+
+// Analyzer reports in-place modification of immutable slices.
+var Analyzer = &analysis.Analyzer{
+	Name:      "immutablecheck",
+	Doc:       "report in-place modification of immutable slices",
+	URL:       "https://pkg.go.dev/github.com/mkeeler/go-immutable/analysis/immutablecheck",
+	Run:       run,
+	FactTypes: []analysis.Fact{new(readonlyType)},
+}
+
+// readonlyType is exported for named types declared with an //immutable:readonly
+// directive so that values of these types are recognized in importing packages too.
+type readonlyType struct{}
+
+func (*readonlyType) AFact() {}
+
+func (*readonlyType) String() string { return "readonly" }
+
+// mutators maps the mutating functions of the slices and sort packages, which modify
+// the slice passed as their first argument, to their immutableslice equivalent.
+var mutators = map[string]map[string]string{
+	"slices": {
+		"Compact":        "Compact",
+		"CompactFunc":    "CompactFunc",
+		"Delete":         "Delete",
+		"DeleteFunc":     "DeleteFunc",
+		"Insert":         "Insert",
+		"Replace":        "Replace",
+		"Reverse":        "Reverse",
+		"Sort":           "Sort",
+		"SortFunc":       "SortFunc",
+		"SortStableFunc": "SortStableFunc",
+	},
+	"sort": {
+		"Float64s":    "Sort",
+		"Ints":        "Sort",
+		"Strings":     "Sort",
+		"Slice":       "SortFunc",
+		"SliceStable": "SortStableFunc",
+	},
+}
+
+type checker struct {
+	pass *analysis.Pass
+	// immutable holds the variables currently known to hold immutable slices.
+	immutable map[types.Object]bool
+}
+
+func run(pass *analysis.Pass) (any, error) {
+	// the immutableslice package itself knows which of its slices are fresh
+	if pass.Pkg.Path() == immutableslicePath {
+		return nil, nil
+	}
+
+	c := &checker{pass: pass, immutable: make(map[types.Object]bool)}
+
+	// facts for the types of this package must be known before checking any file
+	for _, file := range pass.Files {
+		c.exportTypeFacts(file)
+	}
+
+	for _, file := range pass.Files {
+		ast.Inspect(file, c.visit)
+	}
+	return nil, nil
+}
+
+// exportTypeFacts exports a readonlyType fact for every type declared in the file with
+// an //immutable:readonly directive. The directive may be placed on the type spec or on
+// a type declaration holding a single spec.
+func (c *checker) exportTypeFacts(file *ast.File) {
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			ts := spec.(*ast.TypeSpec)
+			_, annotated := parseDirective(ts.Doc)
+			if !annotated && len(gen.Specs) == 1 {
+				_, annotated = parseDirective(gen.Doc)
+			}
+			if obj := c.pass.TypesInfo.Defs[ts.Name]; annotated && obj != nil {
+				c.pass.ExportObjectFact(obj, new(readonlyType))
+			}
+		}
+	}
+}
+
+// parseDirective returns the names listed by an //immutable:readonly directive in the
+// comment group and whether the directive was found.
+func parseDirective(doc *ast.CommentGroup) ([]string, bool) {
+	if doc == nil {
+		return nil, false
+	}
+	for _, comment := range doc.List {
+		rest, ok := strings.CutPrefix(comment.Text, directive)
+		if !ok || (rest != "" && rest[0] != ' ' && rest[0] != '\t') {
+			continue
+		}
+		return strings.FieldsFunc(rest, func(r rune) bool {
+			return r == ',' || r == ' ' || r == '\t'
+		}), true
+	}
+	return nil, false
+}
+
+func (c *checker) visit(n ast.Node) bool {
+	switch n := n.(type) {
+	case *ast.FuncDecl:
+		c.markParams(n)
+	case *ast.AssignStmt:
+		for _, lhs := range n.Lhs {
+			c.checkElementWrite(lhs)
+		}
+		// the values assigned must be checked before tracking the assignment
+		c.inspectAll(n.Lhs)
+		c.inspectAll(n.Rhs)
+		c.track(n.Lhs, n.Rhs)
+		return false
+	case *ast.ValueSpec:
+		c.inspectAll(n.Values)
+		lhs := make([]ast.Expr, len(n.Names))
+		for i, name := range n.Names {
+			lhs[i] = name
+		}
+		c.track(lhs, n.Values)
+		return false
+	case *ast.IncDecStmt:
+		c.checkElementWrite(n.X)
+	case *ast.CallExpr:
+		c.checkCall(n)
+	}
+	return true
+}
+
+func (c *checker) inspectAll(exprs []ast.Expr) {
+	for _, e := range exprs {
+		ast.Inspect(e, c.visit)
+	}
+}
+
+// markParams marks the parameters of a function named by its //immutable:readonly
+// directive as immutable.
+func (c *checker) markParams(decl *ast.FuncDecl) {
+	names, ok := parseDirective(decl.Doc)
+	if !ok {
+		return
+	}
+
+	found := make(map[string]bool)
+	for _, field := range decl.Type.Params.List {
+		for _, name := range field.Names {
+			if len(names) > 0 && !contains(names, name.Name) {
+				continue
+			}
+			found[name.Name] = true
+			if obj := c.pass.TypesInfo.Defs[name]; obj != nil {
+				c.immutable[obj] = true
+			}
+		}
+	}
+
+	for _, name := range names {
+		if !found[name] {
+			c.pass.Reportf(decl.Name.Pos(), "%s names %s which is not a parameter of %s", directive[2:], name, decl.Name.Name)
+		}
+	}
+}
+
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// track updates whether the variables assigned to hold immutable slices. A variable
+// assigned a value which is not immutable is no longer considered immutable.
+func (c *checker) track(lhs, rhs []ast.Expr) {
+	for i, l := range lhs {
+		id, ok := astutil.Unparen(l).(*ast.Ident)
+		if !ok || id.Name == "_" {
+			continue
+		}
+		obj := c.pass.TypesInfo.ObjectOf(id)
+		if obj == nil {
+			continue
+		}
+
+		switch {
+		case len(lhs) == len(rhs):
+			c.immutable[obj] = c.isImmutable(rhs[i])
+		case len(rhs) == 1:
+			// a call with multiple results, of which only the first one may be an
+			// immutable slice, e.g. immutableslice.Merge3
+			c.immutable[obj] = i == 0 && c.isImmutable(rhs[0])
+		default:
+			// a declaration without values
+			c.immutable[obj] = false
+		}
+	}
+}
+
+// isImmutable returns whether the expression evaluates to an immutable slice.
+func (c *checker) isImmutable(e ast.Expr) bool {
+	e = astutil.Unparen(e)
+	if c.isReadonlyType(c.pass.TypesInfo.TypeOf(e)) {
+		return true
+	}
+
+	switch e := e.(type) {
+	case *ast.Ident:
+		obj := c.pass.TypesInfo.ObjectOf(e)
+		return obj != nil && c.immutable[obj]
+	case *ast.SliceExpr:
+		return c.isImmutable(e.X)
+	case *ast.CallExpr:
+		if tv, ok := c.pass.TypesInfo.Types[e.Fun]; ok && tv.IsType() && len(e.Args) == 1 {
+			// a conversion
+			return c.isImmutable(e.Args[0])
+		}
+		fn, ok := typeutil.Callee(c.pass.TypesInfo, e).(*types.Func)
+		if !ok || fn.Pkg() == nil || fn.Pkg().Path() != immutableslicePath || fn.Name() == "Slice" {
+			// Frozen.Slice returns a copy which may be modified
+			return false
+		}
+		// the type of the call has the type parameters of generic functions instantiated
+		t := c.pass.TypesInfo.TypeOf(e)
+		if tuple, ok := t.(*types.Tuple); ok {
+			if tuple.Len() == 0 {
+				return false
+			}
+			t = tuple.At(0).Type()
+		}
+		_, ok = t.Underlying().(*types.Slice)
+		return ok
+	}
+	return false
+}
+
+// isReadonlyType returns whether t is a named type declared with an
+// //immutable:readonly directive.
+func (c *checker) isReadonlyType(t types.Type) bool {
+	named, ok := t.(*types.Named)
+	if !ok {
+		return false
+	}
+	return c.pass.ImportObjectFact(named.Obj(), new(readonlyType))
+}
+
+// checkElementWrite reports assignments to elements of immutable slices, including
+// assignments to fields or array elements of an element, or to values they point to.
+func (c *checker) checkElementWrite(lhs ast.Expr) {
+	for {
+		switch e := lhs.(type) {
+		case *ast.ParenExpr:
+			lhs = e.X
+		case *ast.StarExpr:
+			lhs = e.X
+		case *ast.SelectorExpr:
+			if sel, ok := c.pass.TypesInfo.Selections[e]; !ok || sel.Kind() != types.FieldVal {
+				return
+			}
+			lhs = e.X
+		case *ast.IndexExpr:
+			if _, ok := c.pass.TypesInfo.TypeOf(e.X).Underlying().(*types.Slice); !ok {
+				// an element of an array, or of an array a pointer points to
+				lhs = e.X
+				continue
+			}
+			if c.isImmutable(e.X) {
+				c.pass.Reportf(e.Pos(), "assignment to element of immutable slice %s", types.ExprString(e.X))
+			}
+			return
+		default:
+			return
+		}
+	}
+}
+
+// checkCall reports calls which modify immutable slices passed to them.
+func (c *checker) checkCall(call *ast.CallExpr) {
+	if len(call.Args) == 0 {
+		return
+	}
+	first := call.Args[0]
+
+	switch callee := typeutil.Callee(c.pass.TypesInfo, call).(type) {
+	case *types.Builtin:
+		switch callee.Name() {
+		case "append":
+			// appending to a slice expression limiting the capacity is safe
+			if s, ok := astutil.Unparen(first).(*ast.SliceExpr); ok && s.Slice3 {
+				return
+			}
+			if c.isImmutable(first) {
+				c.pass.Reportf(call.Pos(), "append to immutable slice %s may modify its shared capacity; use immutableslice.Append instead", types.ExprString(first))
+			}
+		case "clear", "copy":
+			if c.isImmutable(first) {
+				c.pass.Reportf(call.Pos(), "%s modifies immutable slice %s", callee.Name(), types.ExprString(first))
+			}
+		}
+	case *types.Func:
+		if callee.Pkg() == nil || !c.isImmutable(first) {
+			return
+		}
+		equivalent, ok := mutators[callee.Pkg().Path()][callee.Name()]
+		if !ok {
+			return
+		}
+		c.pass.Reportf(call.Pos(), "%s.%s modifies immutable slice %s in place; use immutableslice.%s instead",
+			callee.Pkg().Name(), callee.Name(), types.ExprString(first), equivalent)
+	}
+}
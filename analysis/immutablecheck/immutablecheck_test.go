@@ -0,0 +1,11 @@
+package immutablecheck
+
+import (
+	"testing"
+
+	"github.com/mkeeler/go-immutable/internal/analysistest"
+)
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.Run(t, "testdata", Analyzer, "a", "b")
+}
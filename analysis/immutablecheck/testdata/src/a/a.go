@@ -0,0 +1,100 @@
+package a
+
+import (
+	"slices"
+	"sort"
+
+	"github.com/mkeeler/go-immutable/immutableslice"
+)
+
+// Names is shared between callers and must not be modified.
+//
+//immutable:readonly
+type Names []string
+
+func results(s []int) {
+	d := immutableslice.Delete(s, 0, 1)
+	d[0] = 1               // want `assignment to element of immutable slice d`
+	d[1]++                 // want `assignment to element of immutable slice d`
+	_ = append(d, 1)       // want `append to immutable slice d may modify its shared capacity; use immutableslice.Append instead`
+	slices.Sort(d)         // want `slices.Sort modifies immutable slice d in place; use immutableslice.Sort instead`
+	sort.Ints(d[1:])       // want `sort.Ints modifies immutable slice d\[1:\] in place; use immutableslice.Sort instead`
+	clear(d)               // want `clear modifies immutable slice d`
+	copy(d, s)             // want `copy modifies immutable slice d`
+	copy(s, d)             // reading from an immutable slice is fine
+	_ = append(d[:1:1], 2) // the capacity is limited, so a new array is allocated
+
+	// modifying a copy is fine
+	d = slices.Clone(d)
+	d[0] = 1
+	slices.Sort(d)
+
+	immutableslice.Delete(s, 0, 1)[0] = 1 // want `assignment to element of immutable slice immutableslice.Delete\(s, 0, 1\)`
+
+	merged, conflicts := immutableslice.Merge3(s, s, s)
+	merged[0] = 1 // want `assignment to element of immutable slice merged`
+	conflicts[0] = 1
+
+	var a immutableslice.Atomic[[]int, int]
+	loaded := a.Load()
+	slices.Reverse(loaded) // want `slices.Reverse modifies immutable slice loaded in place; use immutableslice.Reverse instead`
+
+	var f immutableslice.Frozen[int]
+	fresh := f.Slice()
+	fresh[0] = 1
+
+	s[0] = 1
+	slices.Sort(s)
+}
+
+// readonly documents that s and t are not modified.
+//
+//immutable:readonly s, t
+func readonly(s, t []string, u []string) {
+	s[0] = ""                                                 // want `assignment to element of immutable slice s`
+	sort.Slice(t, func(i, j int) bool { return t[i] < t[j] }) // want `sort.Slice modifies immutable slice t in place; use immutableslice.SortFunc instead`
+	u[0] = ""
+
+	alias := s[1:]
+	alias[0] = "" // want `assignment to element of immutable slice alias`
+}
+
+//immutable:readonly
+func allParams(s []int, m map[string]int) {
+	s[0] = 1 // want `assignment to element of immutable slice s`
+	m["a"] = 1
+}
+
+type point struct {
+	X, Y int
+	arr  [2]int
+	next *point
+}
+
+type counter struct{ n int }
+
+//immutable:readonly
+func elementFields(ps []point, s []counter, pp []*point, i int) {
+	ps[0].X = 3           // want `assignment to element of immutable slice ps`
+	s[i].n++              // want `assignment to element of immutable slice s`
+	(ps[1]).Y += 1        // want `assignment to element of immutable slice ps`
+	ps[0].arr[1] = 2      // want `assignment to element of immutable slice ps`
+	pp[0].X = 1           // want `assignment to element of immutable slice pp`
+	*pp[1] = point{}      // want `assignment to element of immutable slice pp`
+	ps[0].next.next = nil // want `assignment to element of immutable slice ps`
+
+	p := ps[0]
+	p.X = 3
+}
+
+//immutable:readonly x
+func unknownParam(s []int) {} // want `immutable:readonly names x which is not a parameter of unknownParam`
+
+func namedType(n Names, names []Names) {
+	n[0] = ""                // want `assignment to element of immutable slice n`
+	names[0][0] = ""         // want `assignment to element of immutable slice names\[0\]`
+	slices.Insert(n, 0, "a") // want `slices.Insert modifies immutable slice n in place; use immutableslice.Insert instead`
+	plain := []string(n)
+	plain[0] = "" // want `assignment to element of immutable slice plain`
+	names[0] = nil
+}
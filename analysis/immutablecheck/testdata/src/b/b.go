@@ -0,0 +1,14 @@
+package b
+
+import (
+	"slices"
+
+	"a"
+)
+
+func imported(n a.Names) {
+	slices.Compact(n) // want `slices.Compact modifies immutable slice n in place; use immutableslice.Compact instead`
+	// slices.Clone preserves the type of the slice
+	n = slices.Clone(n)
+	slices.Compact(n) // want `slices.Compact modifies immutable slice n in place; use immutableslice.Compact instead`
+}
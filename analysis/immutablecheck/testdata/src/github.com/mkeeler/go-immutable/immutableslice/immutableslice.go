@@ -0,0 +1,17 @@
+// Package immutableslice is a stub of the real package holding the declarations used by
+// the test packages.
+package immutableslice
+
+func Append[S ~[]E, E any](s S, e ...E) S { return nil }
+
+func Delete[S ~[]E, E any](s S, i, j int) S { return nil }
+
+func Merge3[S ~[]E, E comparable](base, ours, theirs S) (S, []int) { return nil, nil }
+
+type Atomic[S ~[]E, E any] struct{}
+
+func (a *Atomic[S, E]) Load() S { return nil }
+
+type Frozen[E any] struct{}
+
+func (f Frozen[E]) Slice() []E { return nil }
@@ -0,0 +1,691 @@
+// Package immutablefix provides an analyzer which rewrites calls to the mutating
+// functions of the slices and sort packages into calls to their immutableslice
+// equivalents, for slices whose original value may still be used afterwards.
+//
+// Calls which return the modified slice, like slices.Delete, are rewritten by replacing
+// the function:
+//
+//	t := slices.Delete(s, i, j)    =>    t := immutableslice.Delete(s, i, j)
+//
+// Calls which modify the slice in place, like slices.Sort, additionally assign the
+// result back to the slice:
+//
+//	slices.Sort(s)    =>    s = immutableslice.Sort(s)
+//
+// The assignment only preserves the behavior of the code when the modified slice is
+// read afterwards through what it is assigned to: a local variable read after the call,
+// or a field or package level variable which outlives the function. Calls modifying
+// parameters, variables captured by a closure or fields of values copied into the
+// function, whose callers expect to see the modification, and calls whose result would
+// never be read are reported without a fix. So are calls returning the modified slice
+// whose result is discarded.
+//
+// The less functions of sort.Slice and sort.SliceStable are converted into comparison
+// functions of elements when they consist of a single return statement which only
+// accesses the slice at the two indexes. Other calls of these functions are reported
+// without a fix.
+//
+// The original value of a slice is considered to be used afterwards when the slice is a
+// parameter, a variable declared outside of the function or a field, which callers may
+// share, when it is a local variable which is assigned any value other than a newly
+// allocated slice, or, for calls returning the modified slice, when the variable is
+// used after the call without having the result assigned to it.
+package immutablefix
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+const immutableslicePath = "github.com/mkeeler/go-immutable/immutableslice"
+
+// Analyzer reports calls modifying slices whose original value may be used afterwards
+// and suggests rewriting them to the immutableslice equivalents.
+var Analyzer = &analysis.Analyzer{
+	Name: "immutablefix",
+	Doc:  "rewrite mutating slices and sort calls to their immutableslice equivalents",
+	URL:  "https://pkg.go.dev/github.com/mkeeler/go-immutable/analysis/immutablefix",
+	Run:  run,
+}
+
+// rewrite describes the immutableslice equivalent of a mutating function.
+type rewrite struct {
+	name string
+	// inPlace is set for functions which do not return the modified slice, so the result
+	// of the equivalent must be assigned back to the slice.
+	inPlace bool
+	// less is set for functions taking a less function of indexes which must be
+	// converted into a comparison function of elements.
+	less bool
+}
+
+var rewrites = map[string]map[string]rewrite{
+	"slices": {
+		"Compact":        {name: "Compact"},
+		"CompactFunc":    {name: "CompactFunc"},
+		"Delete":         {name: "Delete"},
+		"DeleteFunc":     {name: "DeleteFunc"},
+		"Insert":         {name: "Insert"},
+		"Replace":        {name: "Replace"},
+		"Reverse":        {name: "Reverse", inPlace: true},
+		"Sort":           {name: "Sort", inPlace: true},
+		"SortFunc":       {name: "SortFunc", inPlace: true},
+		"SortStableFunc": {name: "SortStableFunc", inPlace: true},
+	},
+	"sort": {
+		"Float64s":    {name: "Sort", inPlace: true},
+		"Ints":        {name: "Sort", inPlace: true},
+		"Strings":     {name: "Sort", inPlace: true},
+		"Slice":       {name: "SortFunc", inPlace: true, less: true},
+		"SliceStable": {name: "SortStableFunc", inPlace: true, less: true},
+	},
+}
+
+type fixer struct {
+	pass *analysis.Pass
+	file *ast.File
+	src  []byte
+}
+
+func run(pass *analysis.Pass) (any, error) {
+	if pass.Pkg.Path() == immutableslicePath {
+		return nil, nil
+	}
+
+	for _, file := range pass.Files {
+		src, err := pass.ReadFile(pass.Fset.File(file.Pos()).Name())
+		if err != nil {
+			return nil, err
+		}
+		f := &fixer{pass: pass, file: file, src: src}
+
+		ast.Inspect(file, func(n ast.Node) bool {
+			if call, ok := n.(*ast.CallExpr); ok {
+				f.check(call)
+			}
+			return true
+		})
+	}
+	return nil, nil
+}
+
+func (f *fixer) check(call *ast.CallExpr) {
+	fn, ok := typeutil.Callee(f.pass.TypesInfo, call).(*types.Func)
+	if !ok || fn.Pkg() == nil || fn.Type().(*types.Signature).Recv() != nil || len(call.Args) == 0 {
+		return
+	}
+	rw, ok := rewrites[fn.Pkg().Path()][fn.Name()]
+	if !ok {
+		return
+	}
+
+	target := call.Args[0]
+	if !isStable(f.pass.TypesInfo, target) {
+		// the slice is not referred to by a variable or field, e.g. it is the result of
+		// a call, so nothing else can observe its original value
+		return
+	}
+
+	path, _ := astutil.PathEnclosingInterval(f.file, call.Pos(), call.End())
+	if !f.originalObserved(target, call, path, rw) {
+		return
+	}
+
+	diag := analysis.Diagnostic{
+		Pos: call.Pos(),
+		End: call.End(),
+		Message: fmt.Sprintf("%s.%s modifies %s whose original value may be used afterwards; use immutableslice.%s instead",
+			fn.Pkg().Name(), fn.Name(), f.text(target), rw.name),
+	}
+	if !f.fixable(target, call, path, rw) {
+		f.pass.Report(diag)
+		return
+	}
+	if edits, ok := f.edits(call, path, rw); ok {
+		diag.SuggestedFixes = []analysis.SuggestedFix{{
+			Message:   "Use immutableslice." + rw.name,
+			TextEdits: edits,
+		}}
+	}
+	f.pass.Report(diag)
+}
+
+// isStable returns whether the expression is a variable or a chain of field selections
+// of a variable, which can be evaluated a second time to assign to it.
+func isStable(info *types.Info, e ast.Expr) bool {
+	switch e := astutil.Unparen(e).(type) {
+	case *ast.Ident:
+		_, ok := info.Uses[e].(*types.Var)
+		return ok
+	case *ast.SelectorExpr:
+		if sel, ok := info.Selections[e]; ok {
+			return sel.Kind() == types.FieldVal && isStable(info, e.X)
+		}
+		// a variable of another package
+		_, ok := info.Uses[e.Sel].(*types.Var)
+		return ok
+	}
+	return false
+}
+
+// originalObserved returns whether the original value of the target slice may be used
+// after the call.
+func (f *fixer) originalObserved(target ast.Expr, call *ast.CallExpr, path []ast.Node, rw rewrite) bool {
+	id, ok := astutil.Unparen(target).(*ast.Ident)
+	if !ok {
+		// fields may be shared with other code
+		return true
+	}
+	v := f.pass.TypesInfo.Uses[id].(*types.Var)
+
+	fnNode, local := f.local(v, path)
+	if !local {
+		// parameters, package level variables and variables captured by a closure
+		return true
+	}
+
+	if !f.onlyFresh(v, fnNode) {
+		return true
+	}
+	if rw.inPlace || f.resultAssignedTo(v, call, path) {
+		// later uses of the variable refer to the modified slice
+		return false
+	}
+	return f.readAfter(v, call, path)
+}
+
+// local returns the function enclosing the call at the end of the path, and whether the
+// variable is declared in its body.
+func (f *fixer) local(v *types.Var, path []ast.Node) (ast.Node, bool) {
+	for _, n := range path {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			return n, v.Pos() > n.Body.Lbrace && v.Pos() < n.Body.Rbrace
+		case *ast.FuncDecl:
+			return n, n.Body != nil && v.Pos() > n.Body.Lbrace && v.Pos() < n.Body.Rbrace
+		}
+	}
+	return nil, false
+}
+
+// fixable returns whether rewriting the call preserves the behavior of the code apart
+// from the original slice being left unmodified.
+func (f *fixer) fixable(target ast.Expr, call *ast.CallExpr, path []ast.Node, rw rewrite) bool {
+	if !rw.inPlace {
+		// the result holds the modification, so it must be used
+		_, discarded := path[1].(*ast.ExprStmt)
+		return !discarded
+	}
+
+	// the modified slice is assigned back to the target, so the target must be read
+	// afterwards for the assignment to have any effect
+	e := astutil.Unparen(target)
+	for {
+		sel, ok := e.(*ast.SelectorExpr)
+		if !ok {
+			break
+		}
+		selection, ok := f.pass.TypesInfo.Selections[sel]
+		if !ok {
+			// a variable of another package
+			return true
+		}
+		if selection.Indirect() {
+			// a field of a value referred to by a pointer, which outlives the call
+			return true
+		}
+		e = astutil.Unparen(sel.X)
+	}
+
+	id, ok := e.(*ast.Ident)
+	if !ok {
+		return false
+	}
+	v := f.pass.TypesInfo.Uses[id].(*types.Var)
+	if v.Parent() == v.Pkg().Scope() {
+		return true
+	}
+	if _, isLocal := f.local(v, path); !isLocal {
+		// parameters and variables captured by a closure, whose modification the caller
+		// expects to see
+		return false
+	}
+	return f.readAfter(v, call, path)
+}
+
+// onlyFresh returns whether the variable is declared by an assignment or declaration
+// and only ever assigned newly allocated slices, which no other variable refers to.
+func (f *fixer) onlyFresh(v *types.Var, fnNode ast.Node) bool {
+	fresh, declared := true, false
+	ast.Inspect(fnNode, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			for i, lhs := range n.Lhs {
+				if id, ok := lhs.(*ast.Ident); ok && f.pass.TypesInfo.ObjectOf(id) == v {
+					declared = declared || f.pass.TypesInfo.Defs[id] == v
+					fresh = fresh && len(n.Lhs) == len(n.Rhs) && f.isFresh(n.Rhs[i], v)
+				}
+			}
+		case *ast.ValueSpec:
+			for i, name := range n.Names {
+				if f.pass.TypesInfo.Defs[name] != v {
+					continue
+				}
+				declared = true
+				if len(n.Values) > 0 {
+					fresh = fresh && len(n.Names) == len(n.Values) && f.isFresh(n.Values[i], v)
+				}
+			}
+		case *ast.RangeStmt:
+			for _, e := range []ast.Expr{n.Key, n.Value} {
+				if id, ok := e.(*ast.Ident); ok && f.pass.TypesInfo.ObjectOf(id) == v {
+					fresh = false
+				}
+			}
+		case *ast.UnaryExpr:
+			// the address of the variable escapes
+			if id, ok := astutil.Unparen(n.X).(*ast.Ident); ok && n.Op == token.AND && f.pass.TypesInfo.Uses[id] == v {
+				fresh = false
+			}
+		}
+		return fresh
+	})
+	return fresh && declared
+}
+
+// isFresh returns whether the expression evaluates to a newly allocated slice, or to
+// the backing array of the variable v itself.
+func (f *fixer) isFresh(e ast.Expr, v *types.Var) bool {
+	e = astutil.Unparen(e)
+	switch e := e.(type) {
+	case *ast.CompositeLit:
+		return true
+	case *ast.Ident:
+		obj := f.pass.TypesInfo.Uses[e]
+		return obj == v || obj == types.Universe.Lookup("nil")
+	case *ast.SliceExpr:
+		return f.isFresh(e.X, v)
+	case *ast.CallExpr:
+		if tv, ok := f.pass.TypesInfo.Types[e.Fun]; ok && tv.IsType() && len(e.Args) == 1 {
+			// a conversion
+			return f.isFresh(e.Args[0], v)
+		}
+		switch fn := typeutil.Callee(f.pass.TypesInfo, e).(type) {
+		case *types.Builtin:
+			switch fn.Name() {
+			case "make":
+				return true
+			case "append":
+				return f.isFresh(e.Args[0], v)
+			}
+		case *types.Func:
+			if fn.Pkg() == nil {
+				return false
+			}
+			switch {
+			case fn.Pkg().Path() == "slices" && fn.Name() == "Clone":
+				return true
+			case fn.Pkg().Path() == immutableslicePath && fn.Type().(*types.Signature).Recv() == nil:
+				return true
+			case fn.Pkg().Path() == "slices" && len(e.Args) > 0:
+				// the functions of the slices package returning a slice return their
+				// first argument, possibly modified or grown
+				_, ok := rewrites["slices"][fn.Name()]
+				return ok && f.isFresh(e.Args[0], v)
+			}
+		}
+	}
+	return false
+}
+
+// resultAssignedTo returns whether the result of the call is assigned to the variable.
+func (f *fixer) resultAssignedTo(v *types.Var, call *ast.CallExpr, path []ast.Node) bool {
+	assign, ok := path[1].(*ast.AssignStmt)
+	if !ok || len(assign.Lhs) != len(assign.Rhs) {
+		return false
+	}
+	for i, rhs := range assign.Rhs {
+		if rhs != call {
+			continue
+		}
+		id, ok := assign.Lhs[i].(*ast.Ident)
+		return ok && f.pass.TypesInfo.ObjectOf(id) == v
+	}
+	return false
+}
+
+// readAfter returns whether the variable is read after the call, including reads before
+// it within a loop enclosing the call. Assignments to the variable are not reads.
+func (f *fixer) readAfter(v *types.Var, call *ast.CallExpr, path []ast.Node) bool {
+	start := call.End()
+	for _, n := range path {
+		if _, ok := n.(*ast.FuncLit); ok {
+			break
+		}
+		switch n.(type) {
+		case *ast.ForStmt, *ast.RangeStmt:
+			start = n.Pos()
+		}
+	}
+
+	// the statements of the function are visited in order, so assignments are seen
+	// before the identifiers they assign to
+	written := make(map[*ast.Ident]bool)
+	read := false
+	fnNode, _ := f.local(v, path)
+	ast.Inspect(fnNode, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			if n.Tok == token.ASSIGN {
+				for _, lhs := range n.Lhs {
+					if id, ok := astutil.Unparen(lhs).(*ast.Ident); ok {
+						written[id] = true
+					}
+				}
+			}
+		case *ast.Ident:
+			if f.pass.TypesInfo.Uses[n] == v && !written[n] && n.Pos() >= start && (n.Pos() < call.Pos() || n.Pos() >= call.End()) {
+				read = true
+			}
+		}
+		return !read
+	})
+	return read
+}
+
+// edits returns the edits rewriting the call, or false if it cannot be rewritten
+// automatically.
+func (f *fixer) edits(call *ast.CallExpr, path []ast.Node, rw rewrite) ([]analysis.TextEdit, bool) {
+	pkgName, edits := f.addImport(immutableslicePath, "immutableslice", call.Pos())
+	fun := pkgName + "." + rw.name
+	if rw.inPlace {
+		if _, ok := path[1].(*ast.ExprStmt); !ok {
+			return nil, false
+		}
+		// the statement starts with the function called
+		fun = f.text(call.Args[0]) + " = " + fun
+	}
+	edits = append(edits, analysis.TextEdit{
+		Pos:     call.Fun.Pos(),
+		End:     call.Fun.End(),
+		NewText: []byte(fun),
+	})
+
+	if rw.less {
+		if len(call.Args) != 2 {
+			return nil, false
+		}
+		cmpFunc, cmpEdits, ok := f.convertLess(call.Args[0], call.Args[1], path[1])
+		if !ok {
+			return nil, false
+		}
+		edits = append(edits, cmpEdits...)
+		edits = append(edits, analysis.TextEdit{
+			Pos:     call.Args[1].Pos(),
+			End:     call.Args[1].End(),
+			NewText: []byte(cmpFunc),
+		})
+	}
+	return edits, true
+}
+
+// convertLess converts a less function of indexes of the target slice, like
+//
+//	func(i, j int) bool { return s[i].Name < s[j].Name }
+//
+// into a comparison function of its elements, along with the edits adding the imports
+// it requires.
+func (f *fixer) convertLess(target, less ast.Expr, stmt ast.Node) (string, []analysis.TextEdit, bool) {
+	lit, ok := astutil.Unparen(less).(*ast.FuncLit)
+	if !ok || len(lit.Body.List) != 1 {
+		return "", nil, false
+	}
+	ret, ok := lit.Body.List[0].(*ast.ReturnStmt)
+	if !ok || len(ret.Results) != 1 {
+		return "", nil, false
+	}
+	var params []types.Object
+	for _, field := range lit.Type.Params.List {
+		for _, name := range field.Names {
+			params = append(params, f.pass.TypesInfo.Defs[name])
+		}
+	}
+	if len(params) != 2 || params[0] == nil || params[1] == nil {
+		return "", nil, false
+	}
+	slice, ok := f.pass.TypesInfo.TypeOf(target).Underlying().(*types.Slice)
+	if !ok {
+		return "", nil, false
+	}
+
+	// every use of the indexes must be an index expression of the target slice
+	body := ret.Results[0]
+	accesses := make(map[*ast.IndexExpr]int)
+	names := make(map[string]bool)
+	valid := true
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.IndexExpr:
+			if id, ok := astutil.Unparen(n.Index).(*ast.Ident); ok && f.text(n.X) == f.text(target) {
+				for k, p := range params {
+					if f.pass.TypesInfo.Uses[id] == p {
+						accesses[n] = k
+						return false
+					}
+				}
+			}
+		case *ast.Ident:
+			names[n.Name] = true
+			if obj := f.pass.TypesInfo.Uses[n]; obj == params[0] || obj == params[1] {
+				valid = false
+			}
+		}
+		return true
+	})
+	if !valid {
+		return "", nil, false
+	}
+
+	// pick names for the elements which do not shadow anything used by the function
+	a, b := "a", "b"
+	for i := 1; names[a] || names[b]; i++ {
+		a, b = fmt.Sprintf("a%d", i), fmt.Sprintf("b%d", i)
+	}
+
+	var edits []analysis.TextEdit
+	qualifier := func(p *types.Package) string {
+		if p == f.pass.Pkg {
+			return ""
+		}
+		name, importEdits := f.addImport(p.Path(), p.Name(), less.Pos())
+		edits = append(edits, importEdits...)
+		return name
+	}
+	elem := types.TypeString(slice.Elem(), qualifier)
+
+	// less(i, j) with the accesses of i and j replaced by x and y respectively
+	substitute := func(e ast.Expr, x, y string) string {
+		return f.replace(e, accesses, []string{x, y})
+	}
+
+	// comparisons of the same value of both elements become a call of cmp.Compare
+	if bin, ok := astutil.Unparen(body).(*ast.BinaryExpr); ok && (bin.Op == token.LSS || bin.Op == token.GTR) {
+		left, right := substitute(bin.X, "%", "%"), substitute(bin.Y, "%", "%")
+		usesI, usesJ := f.uses(bin.X, accesses), f.uses(bin.Y, accesses)
+		if left == right && len(usesI) == 1 && len(usesJ) == 1 && usesI[0] != usesJ[0] {
+			x, y := substitute(bin.X, a, b), substitute(bin.Y, a, b)
+			if bin.Op == token.GTR {
+				x, y = y, x
+			}
+			cmpName, cmpEdits := f.addImport("cmp", "cmp", less.Pos())
+			edits = append(edits, cmpEdits...)
+			return fmt.Sprintf("func(%s, %s %s) int { return %s.Compare(%s, %s) }", a, b, elem, cmpName, x, y), edits, true
+		}
+	}
+
+	indent := strings.Repeat("\t", f.pass.Fset.Position(stmt.Pos()).Column-1)
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "func(%s, %s %s) int {\n", a, b, elem)
+	fmt.Fprintf(&buf, "%s\tif %s {\n%s\t\treturn -1\n%s\t}\n", indent, substitute(body, a, b), indent, indent)
+	fmt.Fprintf(&buf, "%s\tif %s {\n%s\t\treturn 1\n%s\t}\n", indent, substitute(body, b, a), indent, indent)
+	fmt.Fprintf(&buf, "%s\treturn 0\n%s}", indent, indent)
+	return buf.String(), edits, true
+}
+
+// uses returns the indexes of the parameters of the less function accessed within e.
+func (f *fixer) uses(e ast.Expr, accesses map[*ast.IndexExpr]int) []int {
+	var found []int
+	ast.Inspect(e, func(n ast.Node) bool {
+		if idx, ok := n.(*ast.IndexExpr); ok {
+			if k, ok := accesses[idx]; ok {
+				found = append(found, k)
+				return false
+			}
+		}
+		return true
+	})
+	return found
+}
+
+// replace returns the source of e with every access of the slice replaced by the name
+// for the parameter used as the index.
+func (f *fixer) replace(e ast.Expr, accesses map[*ast.IndexExpr]int, names []string) string {
+	var buf bytes.Buffer
+	pos := e.Pos()
+	ast.Inspect(e, func(n ast.Node) bool {
+		idx, ok := n.(*ast.IndexExpr)
+		if !ok {
+			return true
+		}
+		k, ok := accesses[idx]
+		if !ok {
+			return true
+		}
+		buf.Write(f.source(pos, idx.Pos()))
+		buf.WriteString(names[k])
+		pos = idx.End()
+		return false
+	})
+	buf.Write(f.source(pos, e.End()))
+	return buf.String()
+}
+
+// addImport returns the name referring at pos to the package with the path and name,
+// along with the edits adding an import of the package if the file does not import it
+// under a name visible at pos. The new import is renamed if its name is already taken
+// at pos.
+func (f *fixer) addImport(path, name string, pos token.Pos) (string, []analysis.TextEdit) {
+	scope := f.pass.Pkg.Scope().Innermost(pos)
+	if scope == nil {
+		scope = f.pass.Pkg.Scope()
+	}
+
+	for _, imp := range f.file.Imports {
+		if importPath(imp) != path {
+			continue
+		}
+		var obj types.Object
+		if imp.Name != nil {
+			obj = f.pass.TypesInfo.Defs[imp.Name]
+		} else {
+			obj = f.pass.TypesInfo.Implicits[imp]
+		}
+		if pkgName, ok := obj.(*types.PkgName); ok {
+			if _, found := scope.LookupParent(pkgName.Name(), pos); found == pkgName {
+				return pkgName.Name(), nil
+			}
+		}
+	}
+
+	alias := name
+	for i := 2; !isFree(scope, alias, pos); i++ {
+		alias = fmt.Sprintf("%s%d", name, i)
+	}
+	spec := strconv.Quote(path)
+	if alias != path[strings.LastIndex(path, "/")+1:] {
+		spec = alias + " " + spec
+	}
+
+	var decl *ast.GenDecl
+	for _, d := range f.file.Decls {
+		if gen, ok := d.(*ast.GenDecl); ok && gen.Tok == token.IMPORT {
+			decl = gen
+			break
+		}
+	}
+
+	switch {
+	case decl == nil:
+		return alias, []analysis.TextEdit{{
+			Pos:     f.file.Name.End(),
+			End:     f.file.Name.End(),
+			NewText: []byte("\n\nimport " + spec),
+		}}
+	case !decl.Lparen.IsValid():
+		// the declaration is turned into a group by edits which are identical for every
+		// import added, so the fixes of a file can be applied together. Insertions at the
+		// same position are applied in the order of their text, which puts the closing
+		// parenthesis after the specs inserted after the existing one.
+		existing := decl.Specs[0].(*ast.ImportSpec)
+		edits := []analysis.TextEdit{
+			{Pos: decl.Pos(), End: existing.Pos(), NewText: []byte("import (\n\t")},
+			{Pos: existing.End(), End: existing.End(), NewText: []byte("\n)")},
+		}
+		switch {
+		case isStd(path) == isStd(importPath(existing)):
+			edits = append(edits, analysis.TextEdit{Pos: existing.End(), End: existing.End(), NewText: []byte("\n\t" + spec)})
+		case isStd(path):
+			// the standard library is imported in the first group
+			edits = append(edits, analysis.TextEdit{Pos: existing.Pos(), End: existing.Pos(), NewText: []byte(spec + "\n\n\t")})
+		default:
+			edits = append(edits, analysis.TextEdit{Pos: existing.End(), End: existing.End(), NewText: []byte("\n\n\t" + spec)})
+		}
+		return alias, edits
+	case isStd(path):
+		// the standard library is imported in the first group
+		first := decl.Specs[0].Pos()
+		return alias, []analysis.TextEdit{{Pos: first, End: first, NewText: []byte(spec + "\n\t")}}
+	case isStd(importPath(decl.Specs[len(decl.Specs)-1])):
+		// start a new group after the standard library
+		return alias, []analysis.TextEdit{{Pos: decl.Rparen, End: decl.Rparen, NewText: []byte("\n\t" + spec + "\n")}}
+	default:
+		return alias, []analysis.TextEdit{{Pos: decl.Rparen, End: decl.Rparen, NewText: []byte("\t" + spec + "\n")}}
+	}
+}
+
+// isFree returns whether the name does not refer to anything at pos other than a
+// predeclared identifier, which an import may shadow.
+func isFree(scope *types.Scope, name string, pos token.Pos) bool {
+	_, obj := scope.LookupParent(name, pos)
+	return obj == nil || obj.Parent() == types.Universe
+}
+
+// isStd returns whether the import path belongs to the standard library, whose paths do
+// not start with a domain name.
+func isStd(path string) bool {
+	first, _, _ := strings.Cut(path, "/")
+	return !strings.Contains(first, ".")
+}
+
+func importPath(spec ast.Spec) string {
+	path, _ := strconv.Unquote(spec.(*ast.ImportSpec).Path.Value)
+	return path
+}
+
+func (f *fixer) text(n ast.Node) string {
+	return string(f.source(n.Pos(), n.End()))
+}
+
+func (f *fixer) source(pos, end token.Pos) []byte {
+	file := f.pass.Fset.File(f.file.Pos())
+	return f.src[file.Offset(pos):file.Offset(end)]
+}
@@ -0,0 +1,11 @@
+package immutablefix
+
+import (
+	"testing"
+
+	"github.com/mkeeler/go-immutable/internal/analysistest"
+)
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.RunWithSuggestedFixes(t, "testdata", Analyzer, "a", "b")
+}
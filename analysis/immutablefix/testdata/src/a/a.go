@@ -0,0 +1,98 @@
+package a
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+)
+
+type Event struct {
+	Name string
+	At   time.Time
+}
+
+type Log struct {
+	events []Event
+}
+
+var global []int
+
+func params(s []int, names []string) {
+	// callers expect to see parameters modified in place, so these are not rewritten
+	slices.Sort(s)              // want `slices.Sort modifies s whose original value may be used afterwards; use immutableslice.Sort instead`
+	sort.Strings(names)         // want `sort.Strings modifies names whose original value may be used afterwards; use immutableslice.Sort instead`
+	t := slices.Delete(s, 0, 1) // want `slices.Delete modifies s whose original value may be used afterwards; use immutableslice.Delete instead`
+	fmt.Println(t)
+	slices.Delete(s, 0, 1) // want `slices.Delete modifies s whose original value may be used afterwards; use immutableslice.Delete instead`
+}
+
+func locals() {
+	// newly allocated slices which are not used afterwards are left alone
+	s := make([]int, 3)
+	slices.Sort(s)
+	s = slices.Insert(s, 0, 1)
+	fmt.Println(s)
+
+	// the original value is used after the call
+	u := []int{3, 1, 2}
+	v := slices.Insert(u, 1, 4) // want `slices.Insert modifies u whose original value may be used afterwards; use immutableslice.Insert instead`
+	fmt.Println(u, v)
+
+	// the variable may share its backing array with another variable
+	w := u[1:]
+	slices.Reverse(w) // want `slices.Reverse modifies w whose original value may be used afterwards; use immutableslice.Reverse instead`
+	fmt.Println(w)
+
+	// assigning the result to a variable which is not read afterwards has no effect
+	z := u[:2]
+	slices.Reverse(z) // want `slices.Reverse modifies z whose original value may be used afterwards; use immutableslice.Reverse instead`
+	z = nil
+
+	// uses before the call within a loop happen after it in the next iteration
+	x := []int{1, 2, 3}
+	for i := 0; i < 2; i++ {
+		fmt.Println(x)
+		y := slices.Delete(x, 0, 1) // want `slices.Delete modifies x whose original value may be used afterwards; use immutableslice.Delete instead`
+		fmt.Println(y)
+	}
+
+	// the result of a call is never observed elsewhere
+	slices.Sort(slices.Clone(u))
+
+	// the enclosing function expects to see captured variables modified
+	func() {
+		slices.Sort(u) // want `slices.Sort modifies u whose original value may be used afterwards; use immutableslice.Sort instead`
+	}()
+}
+
+func packageLevel() {
+	sort.Ints(global) // want `sort.Ints modifies global whose original value may be used afterwards; use immutableslice.Sort instead`
+}
+
+func (l Log) value() {
+	// the receiver is a copy, so assigning to its field has no effect
+	slices.SortFunc(l.events, func(a, b Event) int { return a.At.Compare(b.At) }) // want `slices.SortFunc modifies l.events whose original value may be used afterwards; use immutableslice.SortFunc instead`
+}
+
+func (l *Log) pointer() {
+	slices.SortFunc(l.events, func(a, b Event) int { return a.At.Compare(b.At) }) // want `slices.SortFunc modifies l.events whose original value may be used afterwards; use immutableslice.SortFunc instead`
+
+	c := *l
+	slices.Reverse(c.events) // want `slices.Reverse modifies c.events whose original value may be used afterwards; use immutableslice.Reverse instead`
+	fmt.Println(c)
+}
+
+func (l *Log) less(names []string) {
+	sort.Slice(l.events, func(i, j int) bool { return l.events[i].Name < l.events[j].Name })         // want `sort.Slice modifies l.events`
+	sort.SliceStable(l.events, func(i, j int) bool { return l.events[j].At.Before(l.events[i].At) }) // want `sort.SliceStable modifies l.events`
+
+	sorted := names
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] > sorted[j] }) // want `sort.Slice modifies sorted`
+
+	// less functions using the indexes otherwise cannot be converted
+	sort.Slice(sorted, func(i, j int) bool { return i < j }) // want `sort.Slice modifies sorted`
+	a := 1
+	sort.Slice(sorted, func(i, j int) bool { return len(sorted[i]) < len(sorted[j])+a }) // want `sort.Slice modifies sorted`
+	fmt.Println(sorted)
+}
@@ -0,0 +1,19 @@
+package b
+
+import "sort"
+
+type Scores struct {
+	values []int
+}
+
+// the imports are added to the single import of the file
+func (s *Scores) Sorted() []int {
+	sort.Slice(s.values, func(i, j int) bool { return s.values[i] < s.values[j] }) // want `sort.Slice modifies s.values whose original value may be used afterwards; use immutableslice.SortFunc instead`
+	return s.values
+}
+
+// the parameter shadows the name of the cmp package
+func (s *Scores) Top(cmp int) []int {
+	sort.Slice(s.values, func(i, j int) bool { return s.values[i] > s.values[j] }) // want `sort.Slice modifies s.values whose original value may be used afterwards; use immutableslice.SortFunc instead`
+	return s.values[:cmp]
+}
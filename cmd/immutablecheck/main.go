@@ -0,0 +1,21 @@
+// Command immutablecheck reports in-place modification of immutable slices. See the
+// analysis/immutablecheck package for the rules it checks.
+//
+// It may be run on its own:
+//
+//	immutablecheck ./...
+//
+// or as a vet tool:
+//
+//	go vet -vettool=$(which immutablecheck) ./...
+package main
+
+import (
+	"golang.org/x/tools/go/analysis/singlechecker"
+
+	"github.com/mkeeler/go-immutable/analysis/immutablecheck"
+)
+
+func main() {
+	singlechecker.Main(immutablecheck.Analyzer)
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/go/packages"
+
+	"github.com/mkeeler/go-immutable/analysis/immutablefix"
+	"github.com/mkeeler/go-immutable/internal/textedit"
+)
+
+// analyze loads the packages matching the patterns and returns the diagnostics the
+// analyzer reports for them, without duplicates for files belonging to several packages
+// such as the package and its test variant.
+func analyze(patterns []string, tests bool) (*token.FileSet, []analysis.Diagnostic, error) {
+	// the dependencies are type checked from source as the export data of newer Go
+	// versions cannot be read by all supported versions of go/packages
+	conf := &packages.Config{Mode: packages.LoadAllSyntax, Tests: tests}
+	pkgs, err := packages.Load(conf, patterns...)
+	if err != nil {
+		return nil, nil, err
+	}
+	if packages.PrintErrors(pkgs) > 0 {
+		return nil, nil, fmt.Errorf("packages contain errors")
+	}
+
+	type key struct {
+		pos     token.Pos
+		message string
+	}
+	seen := make(map[key]bool)
+	var diagnostics []analysis.Diagnostic
+	for _, pkg := range pkgs {
+		pass := &analysis.Pass{
+			Analyzer:   immutablefix.Analyzer,
+			Fset:       pkg.Fset,
+			Files:      pkg.Syntax,
+			OtherFiles: pkg.OtherFiles,
+			Pkg:        pkg.Types,
+			TypesInfo:  pkg.TypesInfo,
+			TypesSizes: pkg.TypesSizes,
+			ResultOf:   make(map[*analysis.Analyzer]any),
+			ReadFile:   os.ReadFile,
+			Report: func(d analysis.Diagnostic) {
+				k := key{d.Pos, d.Message}
+				if !seen[k] {
+					seen[k] = true
+					diagnostics = append(diagnostics, d)
+				}
+			},
+		}
+		if _, err := immutablefix.Analyzer.Run(pass); err != nil {
+			return nil, nil, fmt.Errorf("analyzing %s: %w", pkg.PkgPath, err)
+		}
+	}
+
+	var fset *token.FileSet
+	if len(pkgs) > 0 {
+		fset = pkgs[0].Fset
+	}
+	// the files are parsed concurrently, so their positions are not ordered by file name
+	sort.Slice(diagnostics, func(i, j int) bool {
+		a, b := fset.Position(diagnostics[i].Pos), fset.Position(diagnostics[j].Pos)
+		if a.Filename != b.Filename {
+			return a.Filename < b.Filename
+		}
+		return a.Offset < b.Offset
+	})
+	return fset, diagnostics, nil
+}
+
+// file is a file rewritten by the fixes.
+type file struct {
+	name     string
+	original []byte
+	fixed    []byte
+}
+
+// applyFixes applies the suggested fixes of the diagnostics and returns the rewritten
+// files sorted by name. Imports of the slices and sort packages which become unused are
+// removed and the files are formatted.
+func applyFixes(fset *token.FileSet, diagnostics []analysis.Diagnostic) ([]file, error) {
+	var all []analysis.TextEdit
+	for _, d := range diagnostics {
+		for _, fix := range d.SuggestedFixes {
+			all = append(all, fix.TextEdits...)
+		}
+	}
+	edits := textedit.ByFile(fset, all)
+
+	names := make([]string, 0, len(edits))
+	for name := range edits {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	files := make([]file, 0, len(names))
+	for _, name := range names {
+		original, err := os.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		fixed, err := textedit.Apply(original, edits[name])
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		fixed, err = cleanup(name, fixed)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		files = append(files, file{name: name, original: original, fixed: fixed})
+	}
+	return files, nil
+}
+
+// cleanup removes the imports of the slices and sort packages if the rewrites removed
+// their last use, and formats the source.
+func cleanup(name string, src []byte) ([]byte, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, name, src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range []string{"slices", "sort"} {
+		if !astutil.UsesImport(f, path) {
+			astutil.DeleteImport(fset, f, path)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fset, f); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeDiff(w io.Writer, f file) error {
+	name := relative(f.name)
+	return difflib.WriteUnifiedDiff(w, difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(f.original)),
+		B:        difflib.SplitLines(string(f.fixed)),
+		FromFile: "a/" + name,
+		ToFile:   "b/" + name,
+		Context:  3,
+	})
+}
+
+// relative returns the path relative to the working directory if it is within it.
+func relative(path string) string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return path
+	}
+	rel, err := filepath.Rel(wd, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return path
+	}
+	return filepath.ToSlash(rel)
+}
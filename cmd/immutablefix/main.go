@@ -0,0 +1,90 @@
+// Command immutablefix rewrites calls to the mutating functions of the slices and sort
+// packages into calls to their immutableslice equivalents, for slices whose original
+// value may still be used afterwards. See the analysis/immutablefix package for the
+// rewrites it makes.
+//
+// Usage:
+//
+//	immutablefix [-diff | -fix] [-test=false] packages...
+//
+// By default it only reports the calls to rewrite. With -diff it prints the rewrites as
+// a unified diff without modifying any file, and with -fix it applies them to the files.
+// Imports of the slices and sort packages which become unused are removed and the
+// rewritten files are formatted like gofmt does. Calls which cannot be rewritten
+// automatically are reported in all modes.
+//
+// It exits with status 3 if any calls were reported without -diff or -fix.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("immutablefix", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	diff := flags.Bool("diff", false, "print the rewrites as a unified diff instead of applying them")
+	fix := flags.Bool("fix", false, "apply the rewrites")
+	tests := flags.Bool("test", true, "also analyze test files")
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "usage: immutablefix [-diff | -fix] [-test=false] packages...\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() == 0 || (*diff && *fix) {
+		flags.Usage()
+		return 2
+	}
+
+	fset, diagnostics, err := analyze(flags.Args(), *tests)
+	if err != nil {
+		fmt.Fprintf(stderr, "immutablefix: %v\n", err)
+		return 1
+	}
+
+	if !*diff && !*fix {
+		for _, d := range diagnostics {
+			fmt.Fprintf(stderr, "%s: %s\n", relative(fset.Position(d.Pos).String()), d.Message)
+		}
+		if len(diagnostics) > 0 {
+			return 3
+		}
+		return 0
+	}
+
+	for _, d := range diagnostics {
+		if len(d.SuggestedFixes) == 0 {
+			fmt.Fprintf(stderr, "%s: %s (no automatic fix)\n", relative(fset.Position(d.Pos).String()), d.Message)
+		}
+	}
+
+	files, err := applyFixes(fset, diagnostics)
+	if err != nil {
+		fmt.Fprintf(stderr, "immutablefix: %v\n", err)
+		return 1
+	}
+
+	for _, f := range files {
+		if *diff {
+			if err := writeDiff(stdout, f); err != nil {
+				fmt.Fprintf(stderr, "immutablefix: %v\n", err)
+				return 1
+			}
+			continue
+		}
+		if err := os.WriteFile(f.name, f.fixed, 0o644); err != nil {
+			fmt.Fprintf(stderr, "immutablefix: %v\n", err)
+			return 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+// setup copies the files of the testdata/fix package into a new module which may import the
+// immutableslice package of this module, and changes the working directory to it. It
+// returns the new module directory and the absolute path of testdata/fix.
+func setup(t *testing.T) (string, string) {
+	t.Helper()
+
+	root, err := filepath.Abs(filepath.Join("..", ".."))
+	require.NoError(t, err)
+	testdata, err := filepath.Abs(filepath.Join("testdata", "fix"))
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	names, err := filepath.Glob(filepath.Join(testdata, "*.go"))
+	require.NoError(t, err)
+	for _, name := range names {
+		src, err := os.ReadFile(name)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, filepath.Base(name)), src, 0o644))
+	}
+
+	mod := fmt.Sprintf("module example.com/fix\n\ngo 1.21\n\nrequire github.com/mkeeler/go-immutable v0.0.0\n\nreplace github.com/mkeeler/go-immutable => %s\n", root)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte(mod), 0o644))
+	sum, err := os.ReadFile(filepath.Join(root, "go.sum"))
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.sum"), sum, 0o644))
+
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() { require.NoError(t, os.Chdir(wd)) })
+	return dir, testdata
+}
+
+func golden(t *testing.T, path string, actual []byte) {
+	t.Helper()
+
+	if *update {
+		require.NoError(t, os.WriteFile(path, actual, 0o644))
+	}
+	expected, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, string(expected), string(actual))
+}
+
+func TestReport(t *testing.T) {
+	setup(t)
+
+	var stdout, stderr bytes.Buffer
+	require.Equal(t, 3, run([]string{"./..."}, &stdout, &stderr))
+	require.Empty(t, stdout.String())
+	require.Equal(t, ""+
+		"fix.go:15:2: sort.Slice modifies t.members whose original value may be used afterwards; use immutableslice.SortFunc instead\n"+
+		"fix.go:20:9: slices.Delete modifies t.members whose original value may be used afterwards; use immutableslice.Delete instead\n"+
+		"fix.go:24:2: slices.Sort modifies t.scores whose original value may be used afterwards; use immutableslice.Sort instead\n"+
+		"fix.go:29:2: sort.Slice modifies names whose original value may be used afterwards; use immutableslice.SortFunc instead\n"+
+		"single.go:7:2: sort.Slice modifies ranked whose original value may be used afterwards; use immutableslice.SortFunc instead\n",
+		stderr.String())
+}
+
+func TestDiff(t *testing.T) {
+	dir, testdata := setup(t)
+	before, err := os.ReadFile(filepath.Join(dir, "fix.go"))
+	require.NoError(t, err)
+
+	var stdout, stderr bytes.Buffer
+	require.Equal(t, 0, run([]string{"-diff", "./..."}, &stdout, &stderr))
+	require.Equal(t, "fix.go:29:2: sort.Slice modifies names whose original value may be used afterwards; use immutableslice.SortFunc instead (no automatic fix)\n", stderr.String())
+
+	// the files are left untouched
+	after, err := os.ReadFile(filepath.Join(dir, "fix.go"))
+	require.NoError(t, err)
+	require.Equal(t, string(before), string(after))
+	golden(t, filepath.Join(testdata, "fix.diff"), stdout.Bytes())
+}
+
+func TestFix(t *testing.T) {
+	dir, testdata := setup(t)
+
+	var stdout, stderr bytes.Buffer
+	require.Equal(t, 0, run([]string{"-fix", "./..."}, &stdout, &stderr))
+	require.Empty(t, stdout.String())
+
+	// the rewritten package must load without errors and only the call which could not
+	// be rewritten is still reported
+	stderr.Reset()
+	require.Equal(t, 3, run([]string{"./..."}, &stdout, &stderr))
+	require.Equal(t, "fix.go:31:2: sort.Slice modifies names whose original value may be used afterwards; use immutableslice.SortFunc instead\n", stderr.String())
+
+	for _, name := range []string{"fix.go", "single.go"} {
+		fixed, err := os.ReadFile(filepath.Join(dir, name))
+		require.NoError(t, err)
+		golden(t, filepath.Join(testdata, name+".golden"), fixed)
+	}
+}
+
+func TestUsage(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	require.Equal(t, 2, run(nil, &stdout, &stderr))
+	require.Equal(t, 2, run([]string{"-diff", "-fix", "./..."}, &stdout, &stderr))
+	require.Contains(t, stderr.String(), "usage: immutablefix")
+}
@@ -0,0 +1,31 @@
+package fix
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+)
+
+type Team struct {
+	members []string
+	scores  []int
+}
+
+func (t *Team) Ranked() []string {
+	sort.Slice(t.members, func(i, j int) bool { return t.members[i] < t.members[j] })
+	return t.members
+}
+
+func (t *Team) Without(i int) []string {
+	return slices.Delete(t.members, i, i+1)
+}
+
+func (t *Team) Best() int {
+	slices.Sort(t.scores)
+	return t.scores[len(t.scores)-1]
+}
+
+func Print(names []string) {
+	sort.Slice(names, func(i, j int) bool { return i > j })
+	fmt.Println(names)
+}
@@ -0,0 +1,9 @@
+package fix
+
+import "sort"
+
+func Top(scores []int, cmp int) []int {
+	ranked := scores
+	sort.Slice(ranked, func(i, j int) bool { return ranked[i] > ranked[j] })
+	return append(ranked[:cmp], scores[0])
+}
@@ -0,0 +1,472 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	directive          = "//immutable:gen"
+	immutableslicePath = "github.com/mkeeler/go-immutable/immutableslice"
+)
+
+type fieldKind int
+
+const (
+	plainField fieldKind = iota
+	sliceField
+	mapField
+)
+
+// field is a field of an annotated struct.
+type field struct {
+	name string
+	typ  string
+	// elem is the element type of a slice field.
+	elem string
+	kind fieldKind
+	// view is set by the immutable:"view" tag. The getter of a view field returns the
+	// slice or map itself instead of a copy and its setter does not copy the value.
+	view bool
+}
+
+// structType is a struct annotated with //immutable:gen.
+type structType struct {
+	name string
+	// typeArgs are the names of the type parameters of a generic struct, e.g. "[K, V]".
+	typeArgs string
+	fields   []field
+}
+
+// importSpec is an import of the generated file. The same path may be imported under
+// different names when a source file renames an import which the generated code uses.
+type importSpec struct {
+	name string
+	path string
+}
+
+// generator accumulates the code generated for the structs of a package.
+type generator struct {
+	fset    *token.FileSet
+	pkg     *types.Package
+	info    *types.Info
+	// typeErr is the first error type checking the package, reported if the type of a
+	// field is unknown.
+	typeErr error
+	buf     bytes.Buffer
+	imports map[importSpec]bool
+}
+
+// generate returns the source of the file holding the methods of the structs annotated
+// with //immutable:gen in the package in dir, or nil if there are none. The output file
+// itself is skipped when parsing the package.
+func generate(dir, output string) ([]byte, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &generator{fset: token.NewFileSet(), imports: make(map[importSpec]bool)}
+	var files []*ast.File
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") || name == output {
+			continue
+		}
+
+		file, err := parser.ParseFile(g.fset, filepath.Join(dir, name), nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+	if len(files) == 0 {
+		return nil, nil
+	}
+	pkgName := files[0].Name.Name
+
+	// the fields are classified by their types, which may be named types declared
+	// elsewhere. Errors are ignored as the package commonly uses the generated methods
+	// which are missing from the files checked.
+	g.info = &types.Info{Defs: make(map[*ast.Ident]types.Object)}
+	conf := types.Config{
+		Importer: importer.ForCompiler(g.fset, "source", nil),
+		Error: func(err error) {
+			if g.typeErr == nil {
+				g.typeErr = err
+			}
+		},
+	}
+	g.pkg, _ = conf.Check(pkgName, g.fset, files, g.info)
+
+	var structs []structType
+	for _, file := range files {
+		found, err := g.parseFile(file)
+		if err != nil {
+			return nil, err
+		}
+		structs = append(structs, found...)
+	}
+
+	if len(structs) == 0 {
+		return nil, nil
+	}
+
+	for _, s := range structs {
+		g.generateStruct(s)
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by immutablegen. DO NOT EDIT.\n\npackage %s\n\n", pkgName)
+	if len(g.imports) > 0 {
+		imports := make([]importSpec, 0, len(g.imports))
+		for imp := range g.imports {
+			imports = append(imports, imp)
+		}
+		// imports of the standard library come first, like goimports orders them
+		sort.Slice(imports, func(i, j int) bool {
+			if isStd(imports[i].path) != isStd(imports[j].path) {
+				return isStd(imports[i].path)
+			}
+			if imports[i].path != imports[j].path {
+				return imports[i].path < imports[j].path
+			}
+			return imports[i].name < imports[j].name
+		})
+
+		out.WriteString("import (\n")
+		for i, imp := range imports {
+			if i > 0 && isStd(imports[i-1].path) && !isStd(imp.path) {
+				out.WriteString("\n")
+			}
+			fmt.Fprintf(&out, "\t%s %s\n", imp.name, strconv.Quote(imp.path))
+		}
+		out.WriteString(")\n\n")
+	}
+	out.Write(g.buf.Bytes())
+
+	src, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %w", err)
+	}
+	return src, nil
+}
+
+// parseFile returns the annotated structs of the file. The imports of the file used by
+// the types of their fields are added to the imports of the generated file.
+func (g *generator) parseFile(file *ast.File) ([]structType, error) {
+	var structs []structType
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			ts := spec.(*ast.TypeSpec)
+			if !hasDirective(ts.Doc) && !(len(gen.Specs) == 1 && hasDirective(gen.Doc)) {
+				continue
+			}
+
+			st, ok := ts.Type.(*ast.StructType)
+			if !ok {
+				return nil, fmt.Errorf("%s: %s is annotated with %s but is not a struct", g.fset.Position(ts.Pos()), ts.Name.Name, directive)
+			}
+
+			if ts.TypeParams != nil {
+				for _, p := range ts.TypeParams.List {
+					g.addImports(file, p.Type)
+				}
+			}
+			for _, f := range st.Fields.List {
+				g.addImports(file, f.Type)
+			}
+
+			s, err := g.parseStruct(file, ts, st)
+			if err != nil {
+				return nil, err
+			}
+			structs = append(structs, s)
+		}
+	}
+	return structs, nil
+}
+
+func hasDirective(doc *ast.CommentGroup) bool {
+	if doc == nil {
+		return false
+	}
+	for _, c := range doc.List {
+		if strings.TrimSpace(c.Text) == directive {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *generator) parseStruct(file *ast.File, ts *ast.TypeSpec, st *ast.StructType) (structType, error) {
+	s := structType{name: ts.Name.Name}
+	if ts.TypeParams != nil {
+		var args []string
+		for _, p := range ts.TypeParams.List {
+			for _, n := range p.Names {
+				args = append(args, n.Name)
+			}
+		}
+		s.typeArgs = "[" + strings.Join(args, ", ") + "]"
+	}
+
+	for _, f := range st.Fields.List {
+		if len(f.Names) == 0 {
+			return s, fmt.Errorf("%s: embedded field %s of %s is not supported", g.fset.Position(f.Pos()), g.expr(f.Type), s.name)
+		}
+
+		view := false
+		if f.Tag != nil {
+			tag, err := strconv.Unquote(f.Tag.Value)
+			if err != nil {
+				return s, fmt.Errorf("%s: invalid tag: %w", g.fset.Position(f.Tag.Pos()), err)
+			}
+			switch opt := reflect.StructTag(tag).Get("immutable"); opt {
+			case "":
+			case "view":
+				view = true
+			default:
+				return s, fmt.Errorf("%s: unknown immutable tag option %q", g.fset.Position(f.Tag.Pos()), opt)
+			}
+		}
+
+		for _, n := range f.Names {
+			if n.IsExported() {
+				return s, fmt.Errorf("%s: field %s of %s must be unexported to be immutable", g.fset.Position(n.Pos()), n.Name, s.name)
+			}
+
+			obj, _ := g.info.Defs[n].(*types.Var)
+			if obj == nil || obj.Type() == types.Typ[types.Invalid] {
+				return s, fmt.Errorf("%s: cannot determine the type of field %s of %s: %v", g.fset.Position(n.Pos()), n.Name, s.name, g.typeErr)
+			}
+
+			// named slice and map types are copied like unnamed ones
+			fld := field{name: n.Name, typ: g.expr(f.Type), view: view}
+			switch t := obj.Type().Underlying().(type) {
+			case *types.Slice:
+				fld.kind = sliceField
+				if lit, ok := f.Type.(*ast.ArrayType); ok {
+					fld.elem = g.expr(lit.Elt)
+				} else {
+					fld.elem = types.TypeString(t.Elem(), g.qualifier(file))
+				}
+			case *types.Map:
+				fld.kind = mapField
+			}
+			if view && fld.kind == plainField {
+				return s, fmt.Errorf("%s: field %s of %s is tagged as a view but is not a slice or map", g.fset.Position(n.Pos()), n.Name, s.name)
+			}
+			s.fields = append(s.fields, fld)
+		}
+	}
+	return s, nil
+}
+
+// addImports adds the imports of the file used by the type expression to the imports of
+// the generated file.
+func (g *generator) addImports(file *ast.File, typ ast.Expr) {
+	ast.Inspect(typ, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		pkg, ok := sel.X.(*ast.Ident)
+		if !ok {
+			return true
+		}
+		for _, imp := range file.Imports {
+			p, _ := strconv.Unquote(imp.Path.Value)
+			if imp.Name != nil && imp.Name.Name == pkg.Name {
+				g.imports[importSpec{name: imp.Name.Name, path: p}] = true
+			} else if imp.Name == nil && importName(p) == pkg.Name {
+				g.imports[importSpec{path: p}] = true
+			}
+		}
+		return true
+	})
+}
+
+// qualifier returns the qualifier naming the packages of types outside of the package
+// like the file does, and adds their imports to the imports of the generated file.
+func (g *generator) qualifier(file *ast.File) types.Qualifier {
+	return func(pkg *types.Package) string {
+		if pkg == g.pkg {
+			return ""
+		}
+		for _, imp := range file.Imports {
+			if p, _ := strconv.Unquote(imp.Path.Value); p == pkg.Path() && imp.Name != nil && imp.Name.Name != "_" && imp.Name.Name != "." {
+				g.imports[importSpec{name: imp.Name.Name, path: p}] = true
+				return imp.Name.Name
+			}
+		}
+		g.imports[importSpec{path: pkg.Path()}] = true
+		return pkg.Name()
+	}
+}
+
+// use adds the import of the package with the path to the generated file and returns the
+// name the generated code refers to it by. An import of the path which the types of the
+// fields already require is reused, along with the name it is imported under.
+func (g *generator) use(p string) string {
+	name := ""
+	for imp := range g.imports {
+		if imp.path != p {
+			continue
+		}
+		if imp.name == "" {
+			return importName(p)
+		}
+		if name == "" || imp.name < name {
+			name = imp.name
+		}
+	}
+	if name != "" {
+		return name
+	}
+	g.imports[importSpec{path: p}] = true
+	return importName(p)
+}
+
+// isStd returns whether the import path belongs to the standard library, whose paths do
+// not start with a domain name.
+func isStd(p string) bool {
+	first, _, _ := strings.Cut(p, "/")
+	return !strings.Contains(first, ".")
+}
+
+// importName guesses the name of the package with the import path, ignoring major
+// version suffixes like those of gopkg.in/yaml.v3 or example.com/mod/v2.
+func importName(p string) string {
+	name := path.Base(p)
+	if isVersion(name) {
+		name = path.Base(path.Dir(p))
+	}
+	if i := strings.Index(name, ".v"); i > 0 && isVersion(name[i+1:]) {
+		name = name[:i]
+	}
+	return strings.TrimPrefix(name, "go-")
+}
+
+func isVersion(s string) bool {
+	_, err := strconv.Atoi(strings.TrimPrefix(s, "v"))
+	return strings.HasPrefix(s, "v") && err == nil
+}
+
+func (g *generator) expr(e ast.Expr) string {
+	var buf bytes.Buffer
+	if err := format.Node(&buf, g.fset, e); err != nil {
+		panic(err)
+	}
+	return buf.String()
+}
+
+func (g *generator) printf(format string, args ...any) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+func (g *generator) generateStruct(s structType) {
+	typ := s.name + s.typeArgs
+	recv := strings.ToLower(s.name[:1])
+	if recv == "v" || recv == "i" {
+		// the parameters of the generated methods are named v and i
+		recv = "x"
+	}
+
+	for _, f := range s.fields {
+		method := exportedName(f.name)
+
+		switch {
+		case f.kind == plainField:
+			g.printf("// %s returns the %s of the %s.\n", method, f.name, s.name)
+			g.printf("func (%s %s) %s() %s {\n\treturn %s.%s\n}\n\n", recv, typ, method, f.typ, recv, f.name)
+		case f.view:
+			g.printf("// %s returns the %s of the %s.\n// The returned value is shared and must not be modified.\n", method, f.name, s.name)
+			g.printf("func (%s %s) %s() %s {\n\treturn %s.%s\n}\n\n", recv, typ, method, f.typ, recv, f.name)
+		default:
+			g.printf("// %s returns a copy of the %s of the %s.\n", method, f.name, s.name)
+			g.printf("func (%s %s) %s() %s {\n\treturn %s(%s.%s)\n}\n\n", recv, typ, method, f.typ, g.cloneFunc(f), recv, f.name)
+		}
+
+		switch {
+		case f.kind == plainField:
+			g.printf("// With%s returns a copy of the %s with its %s set to v.\n", method, s.name, f.name)
+			g.printf("func (%s %s) With%s(v %s) %s {\n\t%s.%s = v\n\treturn %s\n}\n\n", recv, typ, method, f.typ, typ, recv, f.name, recv)
+		case f.view:
+			g.printf("// With%s returns a copy of the %s with its %s set to v.\n// The %s shares v, so it must not be modified afterwards.\n", method, s.name, f.name, s.name)
+			g.printf("func (%s %s) With%s(v %s) %s {\n\t%s.%s = v\n\treturn %s\n}\n\n", recv, typ, method, f.typ, typ, recv, f.name, recv)
+		default:
+			g.printf("// With%s returns a copy of the %s with its %s set to a copy of v.\n", method, s.name, f.name)
+			g.printf("func (%s %s) With%s(v %s) %s {\n\t%s.%s = %s(v)\n\treturn %s\n}\n\n", recv, typ, method, f.typ, typ, recv, f.name, g.cloneFunc(f), recv)
+		}
+
+		if f.kind == sliceField {
+			pkg := g.use(immutableslicePath)
+			singular := singularName(method)
+
+			g.printf("// With%sAppended returns a copy of the %s with v appended to its %s.\n", method, s.name, f.name)
+			g.printf("func (%s %s) With%sAppended(v ...%s) %s {\n\t%s.%s = %s.Append(%s.%s, v...)\n\treturn %s\n}\n\n",
+				recv, typ, method, f.elem, typ, recv, f.name, pkg, recv, f.name, recv)
+
+			g.printf("// With%sInserted returns a copy of the %s with v inserted at index i of its %s.\n// It panics if i is out of range.\n", singular, s.name, f.name)
+			g.printf("func (%s %s) With%sInserted(i int, v ...%s) %s {\n\t%s.%s = %s.Insert(%s.%s, i, v...)\n\treturn %s\n}\n\n",
+				recv, typ, singular, f.elem, typ, recv, f.name, pkg, recv, f.name, recv)
+
+			g.printf("// With%sDeleted returns a copy of the %s with the element at index i of its %s\n// removed. It panics if i is out of range.\n", singular, s.name, f.name)
+			g.printf("func (%s %s) With%sDeleted(i int) %s {\n\t%s.%s = %s.Delete(%s.%s, i, i+1)\n\treturn %s\n}\n\n",
+				recv, typ, singular, typ, recv, f.name, pkg, recv, f.name, recv)
+		}
+	}
+}
+
+// cloneFunc returns the function copying the value of a slice or map field.
+func (g *generator) cloneFunc(f field) string {
+	if f.kind == sliceField {
+		return g.use("slices") + ".Clone"
+	}
+	return g.use("maps") + ".Clone"
+}
+
+// initialisms are names which are written in upper case entirely when exported.
+var initialisms = map[string]bool{
+	"api": true, "html": true, "http": true, "id": true, "ip": true, "json": true,
+	"uri": true, "url": true, "uuid": true, "xml": true,
+}
+
+// exportedName returns the name with its first letter in upper case, or the name in upper
+// case if it is a common initialism such as id.
+func exportedName(name string) string {
+	if initialisms[name] {
+		return strings.ToUpper(name)
+	}
+	r, size := utf8.DecodeRuneInString(name)
+	return string(unicode.ToUpper(r)) + name[size:]
+}
+
+// singularName naively turns the name of a slice field into the name of one of its
+// elements by removing a trailing s, e.g. Items becomes Item.
+func singularName(name string) string {
+	if len(name) > 1 && strings.HasSuffix(name, "s") && !strings.HasSuffix(name, "ss") {
+		return name[:len(name)-1]
+	}
+	return name
+}
@@ -0,0 +1,71 @@
+// Command immutablegen generates accessors for structs which must not change after
+// construction. It is meant to be run by go generate:
+//
+//	//go:generate go run github.com/mkeeler/go-immutable/cmd/immutablegen
+//
+// For every struct of the package annotated with an //immutable:gen directive, it
+// generates methods for each of its fields, which must be unexported:
+//
+//   - a getter named after the field with its first letter in upper case, e.g. Items
+//     for a field items, or entirely in upper case for initialisms such as id, returning
+//     a copy of slices and maps and the value of other fields,
+//   - a setter prefixed with With, e.g. WithItems, returning a copy of the struct with
+//     the field set to a copy of the value,
+//   - and for slice fields, WithItemsAppended, WithItemInserted and WithItemDeleted
+//     returning a copy of the struct with the slice changed by the equivalent functions
+//     of the immutableslice package. The s of the field name is dropped for the latter
+//     two.
+//
+// Fields are slice and map fields when their type is a slice or map type, including
+// named types such as a type Tags []string. The package is type checked to find out,
+// ignoring errors caused by uses of the methods being generated.
+//
+// Slice and map fields tagged with immutable:"view" are returned and set without copying
+// them, leaving it to the callers not to modify them.
+//
+// The methods are written to the file named by the -output flag, immutable_gen.go by
+// default, in the directory of the package, which is the current directory unless
+// specified as an argument.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("immutablegen: ")
+
+	output := flag.String("output", "immutable_gen.go", "name of the generated file")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: immutablegen [-output file] [dir]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	dir := "."
+	switch flag.NArg() {
+	case 0:
+	case 1:
+		dir = flag.Arg(0)
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	src, err := generate(dir, *output)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if src == nil {
+		log.Fatalf("no structs annotated with %s in %s", directive, dir)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, *output), src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
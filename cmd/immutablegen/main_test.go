@@ -0,0 +1,112 @@
+package main
+
+import (
+	"flag"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+func TestGenerateGolden(t *testing.T) {
+	dir := filepath.Join("testdata", "order")
+	golden := filepath.Join(dir, "immutable_gen.go")
+
+	actual, err := generate(dir, "immutable_gen.go")
+	require.NoError(t, err)
+
+	if *update {
+		require.NoError(t, os.WriteFile(golden, actual, 0o644))
+	}
+
+	expected, err := os.ReadFile(golden)
+	require.NoError(t, err)
+	require.Equal(t, string(expected), string(actual))
+
+	// the input along with the generated code must type check
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for _, name := range []string{"catalog.go", "order.go", "immutable_gen.go"} {
+		file, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, 0)
+		require.NoError(t, err)
+		files = append(files, file)
+	}
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	_, err = conf.Check("order", fset, files, nil)
+	require.NoError(t, err)
+}
+
+func TestGenerateErrors(t *testing.T) {
+	type testCase struct {
+		src string
+		err string
+	}
+
+	cases := map[string]testCase{
+		"not a struct": {
+			src: "//immutable:gen\ntype T []int\n",
+			err: "T is annotated with //immutable:gen but is not a struct",
+		},
+		"exported field": {
+			src: "//immutable:gen\ntype T struct {\n\tValues []int\n}\n",
+			err: "field Values of T must be unexported to be immutable",
+		},
+		"embedded field": {
+			src: "//immutable:gen\ntype T struct {\n\tfmt.Stringer\n}\n",
+			err: "embedded field fmt.Stringer of T is not supported",
+		},
+		"unknown tag option": {
+			src: "//immutable:gen\ntype T struct {\n\tvalues []int `immutable:\"copy\"`\n}\n",
+			err: `unknown immutable tag option "copy"`,
+		},
+		"unknown field type": {
+			src: "//immutable:gen\ntype T struct {\n\tvalues Missing\n}\n",
+			err: "cannot determine the type of field values of T",
+		},
+		"view of a plain field": {
+			src: "//immutable:gen\ntype T struct {\n\tvalue int `immutable:\"view\"`\n}\n",
+			err: "field value of T is tagged as a view but is not a slice or map",
+		},
+	}
+
+	for name, tcase := range cases {
+		tcase := tcase
+
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "t.go"), []byte("package t\n\n"+tcase.src), 0o644))
+
+			src, err := generate(dir, "immutable_gen.go")
+			require.ErrorContains(t, err, tcase.err)
+			require.Nil(t, src)
+		})
+	}
+}
+
+func TestGenerateNothing(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "t.go"), []byte("package t\n\ntype T struct{}\n"), 0o644))
+
+	src, err := generate(dir, "immutable_gen.go")
+	require.NoError(t, err)
+	require.Nil(t, src)
+}
+
+func TestNames(t *testing.T) {
+	require.Equal(t, "Items", exportedName("items"))
+	require.Equal(t, "ID", exportedName("id"))
+	require.Equal(t, "Item", singularName("Items"))
+	require.Equal(t, "Address", singularName("Address"))
+	require.Equal(t, "Data", singularName("Data"))
+	require.Equal(t, "yaml", importName("gopkg.in/yaml.v3"))
+	require.Equal(t, "mod", importName("example.com/mod/v2"))
+	require.Equal(t, "immutableslice", importName("github.com/mkeeler/go-immutable/immutableslice"))
+}
@@ -0,0 +1,22 @@
+package order
+
+import (
+	is "github.com/mkeeler/go-immutable/immutableslice"
+)
+
+// Catalog lists the items which may be ordered.
+//
+//immutable:gen
+type Catalog struct {
+	name  string
+	items []Item
+}
+
+// SKUs returns the sorted SKUs of the items in the catalog.
+func (c Catalog) SKUs() []string {
+	skus := make([]string, 0, len(c.items))
+	for _, item := range c.items {
+		skus = append(skus, item.SKU)
+	}
+	return is.Sort(skus)
+}
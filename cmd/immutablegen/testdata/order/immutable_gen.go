@@ -0,0 +1,308 @@
+// Code generated by immutablegen. DO NOT EDIT.
+
+package order
+
+import (
+	"maps"
+	"slices"
+	"sort"
+	"time"
+
+	iso "github.com/mkeeler/go-immutable/immutableslice"
+)
+
+// Name returns the name of the Catalog.
+func (c Catalog) Name() string {
+	return c.name
+}
+
+// WithName returns a copy of the Catalog with its name set to v.
+func (c Catalog) WithName(v string) Catalog {
+	c.name = v
+	return c
+}
+
+// Items returns a copy of the items of the Catalog.
+func (c Catalog) Items() []Item {
+	return slices.Clone(c.items)
+}
+
+// WithItems returns a copy of the Catalog with its items set to a copy of v.
+func (c Catalog) WithItems(v []Item) Catalog {
+	c.items = slices.Clone(v)
+	return c
+}
+
+// WithItemsAppended returns a copy of the Catalog with v appended to its items.
+func (c Catalog) WithItemsAppended(v ...Item) Catalog {
+	c.items = iso.Append(c.items, v...)
+	return c
+}
+
+// WithItemInserted returns a copy of the Catalog with v inserted at index i of its items.
+// It panics if i is out of range.
+func (c Catalog) WithItemInserted(i int, v ...Item) Catalog {
+	c.items = iso.Insert(c.items, i, v...)
+	return c
+}
+
+// WithItemDeleted returns a copy of the Catalog with the element at index i of its items
+// removed. It panics if i is out of range.
+func (c Catalog) WithItemDeleted(i int) Catalog {
+	c.items = iso.Delete(c.items, i, i+1)
+	return c
+}
+
+// ID returns the id of the Order.
+func (o Order) ID() string {
+	return o.id
+}
+
+// WithID returns a copy of the Order with its id set to v.
+func (o Order) WithID(v string) Order {
+	o.id = v
+	return o
+}
+
+// Placed returns the placed of the Order.
+func (o Order) Placed() time.Time {
+	return o.placed
+}
+
+// WithPlaced returns a copy of the Order with its placed set to v.
+func (o Order) WithPlaced(v time.Time) Order {
+	o.placed = v
+	return o
+}
+
+// Items returns a copy of the items of the Order.
+func (o Order) Items() []Item {
+	return slices.Clone(o.items)
+}
+
+// WithItems returns a copy of the Order with its items set to a copy of v.
+func (o Order) WithItems(v []Item) Order {
+	o.items = slices.Clone(v)
+	return o
+}
+
+// WithItemsAppended returns a copy of the Order with v appended to its items.
+func (o Order) WithItemsAppended(v ...Item) Order {
+	o.items = iso.Append(o.items, v...)
+	return o
+}
+
+// WithItemInserted returns a copy of the Order with v inserted at index i of its items.
+// It panics if i is out of range.
+func (o Order) WithItemInserted(i int, v ...Item) Order {
+	o.items = iso.Insert(o.items, i, v...)
+	return o
+}
+
+// WithItemDeleted returns a copy of the Order with the element at index i of its items
+// removed. It panics if i is out of range.
+func (o Order) WithItemDeleted(i int) Order {
+	o.items = iso.Delete(o.items, i, i+1)
+	return o
+}
+
+// Notes returns the notes of the Order.
+// The returned value is shared and must not be modified.
+func (o Order) Notes() []string {
+	return o.notes
+}
+
+// WithNotes returns a copy of the Order with its notes set to v.
+// The Order shares v, so it must not be modified afterwards.
+func (o Order) WithNotes(v []string) Order {
+	o.notes = v
+	return o
+}
+
+// WithNotesAppended returns a copy of the Order with v appended to its notes.
+func (o Order) WithNotesAppended(v ...string) Order {
+	o.notes = iso.Append(o.notes, v...)
+	return o
+}
+
+// WithNoteInserted returns a copy of the Order with v inserted at index i of its notes.
+// It panics if i is out of range.
+func (o Order) WithNoteInserted(i int, v ...string) Order {
+	o.notes = iso.Insert(o.notes, i, v...)
+	return o
+}
+
+// WithNoteDeleted returns a copy of the Order with the element at index i of its notes
+// removed. It panics if i is out of range.
+func (o Order) WithNoteDeleted(i int) Order {
+	o.notes = iso.Delete(o.notes, i, i+1)
+	return o
+}
+
+// Metadata returns a copy of the metadata of the Order.
+func (o Order) Metadata() map[string]string {
+	return maps.Clone(o.metadata)
+}
+
+// WithMetadata returns a copy of the Order with its metadata set to a copy of v.
+func (o Order) WithMetadata(v map[string]string) Order {
+	o.metadata = maps.Clone(v)
+	return o
+}
+
+// Frozen returns the frozen of the Order.
+func (o Order) Frozen() iso.Frozen[string] {
+	return o.frozen
+}
+
+// WithFrozen returns a copy of the Order with its frozen set to v.
+func (o Order) WithFrozen(v iso.Frozen[string]) Order {
+	o.frozen = v
+	return o
+}
+
+// Tags returns a copy of the tags of the Order.
+func (o Order) Tags() Tags {
+	return slices.Clone(o.tags)
+}
+
+// WithTags returns a copy of the Order with its tags set to a copy of v.
+func (o Order) WithTags(v Tags) Order {
+	o.tags = slices.Clone(v)
+	return o
+}
+
+// WithTagsAppended returns a copy of the Order with v appended to its tags.
+func (o Order) WithTagsAppended(v ...string) Order {
+	o.tags = iso.Append(o.tags, v...)
+	return o
+}
+
+// WithTagInserted returns a copy of the Order with v inserted at index i of its tags.
+// It panics if i is out of range.
+func (o Order) WithTagInserted(i int, v ...string) Order {
+	o.tags = iso.Insert(o.tags, i, v...)
+	return o
+}
+
+// WithTagDeleted returns a copy of the Order with the element at index i of its tags
+// removed. It panics if i is out of range.
+func (o Order) WithTagDeleted(i int) Order {
+	o.tags = iso.Delete(o.tags, i, i+1)
+	return o
+}
+
+// Labels returns a copy of the labels of the Order.
+func (o Order) Labels() Labels {
+	return maps.Clone(o.labels)
+}
+
+// WithLabels returns a copy of the Order with its labels set to a copy of v.
+func (o Order) WithLabels(v Labels) Order {
+	o.labels = maps.Clone(v)
+	return o
+}
+
+// History returns a copy of the history of the Order.
+func (o Order) History() History {
+	return slices.Clone(o.history)
+}
+
+// WithHistory returns a copy of the Order with its history set to a copy of v.
+func (o Order) WithHistory(v History) Order {
+	o.history = slices.Clone(v)
+	return o
+}
+
+// WithHistoryAppended returns a copy of the Order with v appended to its history.
+func (o Order) WithHistoryAppended(v ...time.Time) Order {
+	o.history = iso.Append(o.history, v...)
+	return o
+}
+
+// WithHistoryInserted returns a copy of the Order with v inserted at index i of its history.
+// It panics if i is out of range.
+func (o Order) WithHistoryInserted(i int, v ...time.Time) Order {
+	o.history = iso.Insert(o.history, i, v...)
+	return o
+}
+
+// WithHistoryDeleted returns a copy of the Order with the element at index i of its history
+// removed. It panics if i is out of range.
+func (o Order) WithHistoryDeleted(i int) Order {
+	o.history = iso.Delete(o.history, i, i+1)
+	return o
+}
+
+// Codes returns a copy of the codes of the Order.
+func (o Order) Codes() sort.StringSlice {
+	return slices.Clone(o.codes)
+}
+
+// WithCodes returns a copy of the Order with its codes set to a copy of v.
+func (o Order) WithCodes(v sort.StringSlice) Order {
+	o.codes = slices.Clone(v)
+	return o
+}
+
+// WithCodesAppended returns a copy of the Order with v appended to its codes.
+func (o Order) WithCodesAppended(v ...string) Order {
+	o.codes = iso.Append(o.codes, v...)
+	return o
+}
+
+// WithCodeInserted returns a copy of the Order with v inserted at index i of its codes.
+// It panics if i is out of range.
+func (o Order) WithCodeInserted(i int, v ...string) Order {
+	o.codes = iso.Insert(o.codes, i, v...)
+	return o
+}
+
+// WithCodeDeleted returns a copy of the Order with the element at index i of its codes
+// removed. It panics if i is out of range.
+func (o Order) WithCodeDeleted(i int) Order {
+	o.codes = iso.Delete(o.codes, i, i+1)
+	return o
+}
+
+// Values returns a copy of the values of the Index.
+func (x Index[K, V]) Values() []V {
+	return slices.Clone(x.values)
+}
+
+// WithValues returns a copy of the Index with its values set to a copy of v.
+func (x Index[K, V]) WithValues(v []V) Index[K, V] {
+	x.values = slices.Clone(v)
+	return x
+}
+
+// WithValuesAppended returns a copy of the Index with v appended to its values.
+func (x Index[K, V]) WithValuesAppended(v ...V) Index[K, V] {
+	x.values = iso.Append(x.values, v...)
+	return x
+}
+
+// WithValueInserted returns a copy of the Index with v inserted at index i of its values.
+// It panics if i is out of range.
+func (x Index[K, V]) WithValueInserted(i int, v ...V) Index[K, V] {
+	x.values = iso.Insert(x.values, i, v...)
+	return x
+}
+
+// WithValueDeleted returns a copy of the Index with the element at index i of its values
+// removed. It panics if i is out of range.
+func (x Index[K, V]) WithValueDeleted(i int) Index[K, V] {
+	x.values = iso.Delete(x.values, i, i+1)
+	return x
+}
+
+// Entries returns a copy of the entries of the Index.
+func (x Index[K, V]) Entries() map[K]int {
+	return maps.Clone(x.entries)
+}
+
+// WithEntries returns a copy of the Index with its entries set to a copy of v.
+func (x Index[K, V]) WithEntries(v map[K]int) Index[K, V] {
+	x.entries = maps.Clone(v)
+	return x
+}
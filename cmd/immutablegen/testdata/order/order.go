@@ -0,0 +1,58 @@
+package order
+
+import (
+	"sort"
+	"time"
+
+	iso "github.com/mkeeler/go-immutable/immutableslice"
+)
+
+// Order is an order placed by a customer.
+//
+//immutable:gen
+type Order struct {
+	id       string
+	placed   time.Time
+	items    []Item
+	notes    []string `immutable:"view"`
+	metadata map[string]string
+	frozen   iso.Frozen[string]
+	tags     Tags
+	labels   Labels
+	history  History
+	codes    sort.StringSlice
+}
+
+// Tags are the tags of an order.
+type Tags []string
+
+// Labels are the labels of an order by name.
+type Labels map[string]string
+
+// History holds the times an order was changed.
+type History []time.Time
+
+// Size returns the number of items of the order. The package may use the generated
+// methods while they are generated again.
+func (o Order) Size() int {
+	return len(o.Items())
+}
+
+// Item is a line item of an order.
+type Item struct {
+	SKU      string
+	Quantity int
+}
+
+// Index maps keys to values.
+//
+//immutable:gen
+type Index[K comparable, V any] struct {
+	values  []V
+	entries map[K]int
+}
+
+// NotGenerated is not annotated.
+type NotGenerated struct {
+	values []int
+}
@@ -2,10 +2,15 @@ module github.com/mkeeler/go-immutable
 
 go 1.21.4
 
-require github.com/stretchr/testify v1.8.4
+require (
+	github.com/pmezard/go-difflib v1.0.0
+	github.com/stretchr/testify v1.8.4
+	golang.org/x/tools v0.24.1
+)
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
-	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/mod v0.20.0 // indirect
+	golang.org/x/sync v0.8.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
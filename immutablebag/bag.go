@@ -0,0 +1,160 @@
+// Package immutablebag provides a persistent multiset, also known as a bag, which keeps
+// track of how many times each element occurs.
+package immutablebag
+
+import (
+	"cmp"
+
+	"github.com/mkeeler/go-immutable/internal/ordmap"
+)
+
+// Bag is a persistent multiset. Each distinct element is stored once along with the
+// number of times it occurs. Elements are kept ordered by a comparison function so that
+// iteration is deterministic. Add and Remove run in O(log n) time where n is the number
+// of distinct elements, and only copy the path to the modified element.
+//
+// Bags must be created with New or NewFunc. A Bag is safe for concurrent use by
+// multiple goroutines as none of its methods modify it.
+type Bag[E any] struct {
+	counts ordmap.Map[E, int]
+	size   int
+}
+
+// New creates a bag holding the specified elements ordered by their natural order. Each
+// occurrence of an element within elems is counted.
+func New[E cmp.Ordered](elems ...E) Bag[E] {
+	return NewFunc(cmp.Compare[E], elems...)
+}
+
+// NewFunc creates a bag holding the specified elements ordered by the cmp function.
+// Elements for which cmp returns zero are considered equal and are counted as
+// occurrences of the same element.
+func NewFunc[E any](cmp func(a, b E) int, elems ...E) Bag[E] {
+	b := Bag[E]{counts: ordmap.New[E, int](cmp)}
+	for _, e := range elems {
+		b = b.Add(e, 1)
+	}
+	return b
+}
+
+// Len returns the total number of occurrences of all elements in the bag.
+func (b Bag[E]) Len() int {
+	return b.size
+}
+
+// Distinct returns the number of distinct elements in the bag.
+func (b Bag[E]) Distinct() int {
+	return b.counts.Len()
+}
+
+// Count returns the number of times e occurs in the bag.
+func (b Bag[E]) Count(e E) int {
+	n, _ := b.counts.Get(e)
+	return n
+}
+
+// Add returns a new bag with n more occurrences of e. It panics if n is negative.
+func (b Bag[E]) Add(e E, n int) Bag[E] {
+	if n < 0 {
+		panic("immutablebag: negative count")
+	}
+	if n == 0 {
+		return b
+	}
+
+	return Bag[E]{
+		counts: b.counts.Set(e, b.Count(e)+n),
+		size:   b.size + n,
+	}
+}
+
+// Remove returns a new bag with up to n fewer occurrences of e. Removing more occurrences
+// than are present removes e entirely. It panics if n is negative.
+func (b Bag[E]) Remove(e E, n int) Bag[E] {
+	if n < 0 {
+		panic("immutablebag: negative count")
+	}
+
+	current := b.Count(e)
+	if n == 0 || current == 0 {
+		return b
+	}
+
+	if n >= current {
+		return Bag[E]{counts: b.counts.Delete(e), size: b.size - current}
+	}
+	return Bag[E]{counts: b.counts.Set(e, current-n), size: b.size - n}
+}
+
+// RangeDistinct calls fn for each distinct element of the bag along with the number of
+// times it occurs. Elements are visited in order. Iteration stops early if fn returns
+// false.
+func (b Bag[E]) RangeDistinct(fn func(e E, count int) bool) {
+	b.counts.Range(fn)
+}
+
+// Slice returns a newly allocated slice holding every occurrence of every element of
+// the bag in order, so that an element occurring three times is repeated three times.
+// An empty bag results in a nil slice.
+func (b Bag[E]) Slice() []E {
+	if b.size == 0 {
+		return nil
+	}
+
+	s := make([]E, 0, b.size)
+	b.counts.Range(func(e E, count int) bool {
+		for i := 0; i < count; i++ {
+			s = append(s, e)
+		}
+		return true
+	})
+	return s
+}
+
+// Sum returns a new bag where each element occurs as many times as it does in b and
+// other combined. The resulting bag uses the ordering of b.
+func (b Bag[E]) Sum(other Bag[E]) Bag[E] {
+	result := b
+	other.counts.Range(func(e E, count int) bool {
+		result = result.Add(e, count)
+		return true
+	})
+	return result
+}
+
+// Union returns a new bag where each element occurs the larger of the number of times
+// it occurs in b and in other. The resulting bag uses the ordering of b.
+func (b Bag[E]) Union(other Bag[E]) Bag[E] {
+	result := b
+	other.counts.Range(func(e E, count int) bool {
+		if current := b.Count(e); count > current {
+			result = result.Add(e, count-current)
+		}
+		return true
+	})
+	return result
+}
+
+// Intersection returns a new bag where each element occurs the smaller of the number
+// of times it occurs in b and in other. The resulting bag uses the ordering of b.
+func (b Bag[E]) Intersection(other Bag[E]) Bag[E] {
+	result := b
+	b.counts.Range(func(e E, count int) bool {
+		if otherCount := other.Count(e); otherCount < count {
+			result = result.Remove(e, count-otherCount)
+		}
+		return true
+	})
+	return result
+}
+
+// Difference returns a new bag where the occurrences of each element in other are
+// removed from b. Elements occurring more often in other than in b are removed entirely.
+func (b Bag[E]) Difference(other Bag[E]) Bag[E] {
+	result := b
+	other.counts.Range(func(e E, count int) bool {
+		result = result.Remove(e, count)
+		return true
+	})
+	return result
+}
@@ -0,0 +1,151 @@
+package immutablebag
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	type testCase struct {
+		elems    []string
+		expected []string
+		distinct int
+	}
+
+	cases := map[string]testCase{
+		"no elements": {
+			elems:    nil,
+			expected: nil,
+			distinct: 0,
+		},
+		"unique elements": {
+			elems:    []string{"b", "a", "c"},
+			expected: []string{"a", "b", "c"},
+			distinct: 3,
+		},
+		"duplicate elements": {
+			elems:    []string{"b", "a", "b", "c", "b", "a"},
+			expected: []string{"a", "a", "b", "b", "b", "c"},
+			distinct: 3,
+		},
+	}
+
+	for name, tcase := range cases {
+		tcase := tcase
+
+		t.Run(name, func(t *testing.T) {
+			t.Run("New", func(t *testing.T) {
+				b := New(tcase.elems...)
+				require.Equal(t, len(tcase.elems), b.Len())
+				require.Equal(t, tcase.distinct, b.Distinct())
+				require.Equal(t, tcase.expected, b.Slice())
+			})
+
+			t.Run("NewFunc", func(t *testing.T) {
+				// compare case insensitively so that upper case variants are
+				// counted as the same element
+				var upper []string
+				for _, e := range tcase.elems {
+					upper = append(upper, strings.ToUpper(e))
+				}
+				b := NewFunc(func(a, b string) int {
+					return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+				}, append(upper, tcase.elems...)...)
+
+				require.Equal(t, 2*len(tcase.elems), b.Len())
+				require.Equal(t, tcase.distinct, b.Distinct())
+			})
+		})
+	}
+}
+
+func TestAddRemove(t *testing.T) {
+	b := New("a", "b", "b")
+
+	added := b.Add("b", 2).Add("c", 1).Add("d", 0)
+	require.Equal(t, 4, added.Count("b"))
+	require.Equal(t, 1, added.Count("c"))
+	require.Equal(t, 0, added.Count("d"))
+	require.Equal(t, 6, added.Len())
+	require.Equal(t, 3, added.Distinct())
+
+	removed := added.Remove("b", 3).Remove("a", 5).Remove("missing", 1)
+	require.Equal(t, 1, removed.Count("b"))
+	require.Equal(t, 0, removed.Count("a"))
+	require.Equal(t, []string{"b", "c"}, removed.Slice())
+	require.Equal(t, 2, removed.Distinct())
+
+	// the original bag is unaffected
+	require.Equal(t, []string{"a", "b", "b"}, b.Slice())
+
+	require.Panics(t, func() { b.Add("a", -1) })
+	require.Panics(t, func() { b.Remove("a", -1) })
+}
+
+func TestRangeDistinct(t *testing.T) {
+	b := New("c", "a", "b", "a", "c", "c")
+
+	type entry struct {
+		elem  string
+		count int
+	}
+
+	var actual []entry
+	b.RangeDistinct(func(e string, count int) bool {
+		actual = append(actual, entry{e, count})
+		return true
+	})
+	require.Equal(t, []entry{{"a", 2}, {"b", 1}, {"c", 3}}, actual)
+
+	var first []entry
+	b.RangeDistinct(func(e string, count int) bool {
+		first = append(first, entry{e, count})
+		return false
+	})
+	require.Equal(t, []entry{{"a", 2}}, first)
+}
+
+func TestBagOperations(t *testing.T) {
+	a := New("x", "x", "x", "y", "z")
+	b := New("x", "y", "y", "w")
+
+	type testCase struct {
+		op       func(a, b Bag[string]) Bag[string]
+		expected []string
+	}
+
+	cases := map[string]testCase{
+		"Sum": {
+			op:       Bag[string].Sum,
+			expected: []string{"w", "x", "x", "x", "x", "y", "y", "y", "z"},
+		},
+		"Union": {
+			op:       Bag[string].Union,
+			expected: []string{"w", "x", "x", "x", "y", "y", "z"},
+		},
+		"Intersection": {
+			op:       Bag[string].Intersection,
+			expected: []string{"x", "y"},
+		},
+		"Difference": {
+			op:       Bag[string].Difference,
+			expected: []string{"x", "x", "z"},
+		},
+	}
+
+	for name, tcase := range cases {
+		tcase := tcase
+
+		t.Run(name, func(t *testing.T) {
+			actual := tcase.op(a, b)
+			require.Equal(t, tcase.expected, actual.Slice())
+			require.Equal(t, len(tcase.expected), actual.Len())
+
+			// the operands are unaffected
+			require.Equal(t, []string{"x", "x", "x", "y", "z"}, a.Slice())
+			require.Equal(t, []string{"w", "x", "y", "y"}, b.Slice())
+		})
+	}
+}
@@ -0,0 +1,244 @@
+// Package immutablebitset provides a persistent compressed set of uint32 values. The
+// representation follows the design of roaring bitmaps: values are partitioned by their
+// high 16 bits into containers which store the low 16 bits either as a sorted array or
+// as a bitmap depending on how dense they are. Updates only replace the containers they
+// touch so new versions of a set share all other containers with the original.
+package immutablebitset
+
+import "sort"
+
+// entry associates the high 16 bits of a group of values with the container holding
+// their low 16 bits.
+type entry struct {
+	key       uint16
+	container *container
+}
+
+// Bitset is a persistent set of uint32 values. The set is never modified after it has
+// been created. Add and Remove copy only the small index of containers and the single
+// affected container while the set operations share every container that is unchanged
+// in the result.
+//
+// The zero value of a Bitset is an empty set ready to use. A Bitset is safe for
+// concurrent use by multiple goroutines as none of its methods modify it.
+type Bitset struct {
+	entries []entry
+	card    uint64
+}
+
+// New creates a set holding the specified values.
+func New(values ...uint32) Bitset {
+	var b Bitset
+	for _, v := range values {
+		b = b.Add(v)
+	}
+	return b
+}
+
+// Cardinality returns the number of values in the set.
+func (b Bitset) Cardinality() uint64 {
+	return b.card
+}
+
+// Contains returns whether v is a member of the set.
+func (b Bitset) Contains(v uint32) bool {
+	idx, found := b.find(uint16(v >> 16))
+	return found && b.entries[idx].container.contains(uint16(v))
+}
+
+// Add returns a new set holding the values of b along with v. If v is already a member
+// of the set, b itself is returned.
+func (b Bitset) Add(v uint32) Bitset {
+	key, low := uint16(v>>16), uint16(v)
+
+	idx, found := b.find(key)
+	if !found {
+		entries := make([]entry, len(b.entries)+1)
+		copy(entries, b.entries[:idx])
+		entries[idx] = entry{key: key, container: newArrayContainer([]uint16{low})}
+		copy(entries[idx+1:], b.entries[idx:])
+		return Bitset{entries: entries, card: b.card + 1}
+	}
+
+	old := b.entries[idx].container
+	updated := old.add(low)
+	if updated == old {
+		return b
+	}
+
+	entries := make([]entry, len(b.entries))
+	copy(entries, b.entries)
+	entries[idx].container = updated
+	return Bitset{entries: entries, card: b.card + 1}
+}
+
+// Remove returns a new set holding the values of b without v. If v is not a member of
+// the set, b itself is returned.
+func (b Bitset) Remove(v uint32) Bitset {
+	key, low := uint16(v>>16), uint16(v)
+
+	idx, found := b.find(key)
+	if !found {
+		return b
+	}
+
+	old := b.entries[idx].container
+	updated := old.remove(low)
+	if updated == old {
+		return b
+	}
+
+	if updated == nil {
+		entries := make([]entry, 0, len(b.entries)-1)
+		entries = append(entries, b.entries[:idx]...)
+		entries = append(entries, b.entries[idx+1:]...)
+		return Bitset{entries: entries, card: b.card - 1}
+	}
+
+	entries := make([]entry, len(b.entries))
+	copy(entries, b.entries)
+	entries[idx].container = updated
+	return Bitset{entries: entries, card: b.card - 1}
+}
+
+// Rank returns the number of values in the set that are less than or equal to v.
+func (b Bitset) Rank(v uint32) uint64 {
+	key, low := uint16(v>>16), uint16(v)
+
+	var rank uint64
+	for _, e := range b.entries {
+		if e.key > key {
+			break
+		}
+		if e.key < key {
+			rank += uint64(e.container.card)
+			continue
+		}
+		rank += uint64(e.container.rank(low))
+	}
+	return rank
+}
+
+// Select returns the i-th smallest value of the set, counting from zero. The boolean
+// return value will be false if the set holds i or fewer values.
+func (b Bitset) Select(i uint64) (uint32, bool) {
+	for _, e := range b.entries {
+		card := uint64(e.container.card)
+		if i >= card {
+			i -= card
+			continue
+		}
+		return uint32(e.key)<<16 | uint32(e.container.selectAt(int(i))), true
+	}
+	return 0, false
+}
+
+// Range calls fn for each value of the set in ascending order. Iteration stops early if
+// fn returns false.
+func (b Bitset) Range(fn func(uint32) bool) {
+	for _, e := range b.entries {
+		high := uint32(e.key) << 16
+		cont := e.container.forEach(func(low uint16) bool {
+			return fn(high | uint32(low))
+		})
+		if !cont {
+			return
+		}
+	}
+}
+
+// Slice returns a newly allocated slice holding the values of the set in ascending
+// order. An empty set results in a nil slice.
+func (b Bitset) Slice() []uint32 {
+	if b.card == 0 {
+		return nil
+	}
+
+	s := make([]uint32, 0, b.card)
+	b.Range(func(v uint32) bool {
+		s = append(s, v)
+		return true
+	})
+	return s
+}
+
+// And returns a new set holding the values present in both b and other.
+func (b Bitset) And(other Bitset) Bitset {
+	return merge(b, other, false, false, func(a, b *container) *container {
+		if a == b {
+			return a
+		}
+		return combine(a, b, func(x, y uint64) uint64 { return x & y })
+	})
+}
+
+// AndNot returns a new set holding the values present in b but not in other.
+func (b Bitset) AndNot(other Bitset) Bitset {
+	return merge(b, other, true, false, func(a, b *container) *container {
+		if a == b {
+			return nil
+		}
+		return combine(a, b, func(x, y uint64) uint64 { return x &^ y })
+	})
+}
+
+// Or returns a new set holding the values present in either b or other.
+func (b Bitset) Or(other Bitset) Bitset {
+	return merge(b, other, true, true, func(a, b *container) *container {
+		if a == b {
+			return a
+		}
+		return combine(a, b, func(x, y uint64) uint64 { return x | y })
+	})
+}
+
+// Xor returns a new set holding the values present in exactly one of b and other.
+func (b Bitset) Xor(other Bitset) Bitset {
+	return merge(b, other, true, true, func(a, b *container) *container {
+		if a == b {
+			return nil
+		}
+		return combine(a, b, func(x, y uint64) uint64 { return x ^ y })
+	})
+}
+
+// find returns the index of the entry for key along with whether it exists. When it
+// does not exist, the index is where it would need to be inserted.
+func (b Bitset) find(key uint16) (int, bool) {
+	idx := sort.Search(len(b.entries), func(i int) bool { return b.entries[i].key >= key })
+	return idx, idx < len(b.entries) && b.entries[idx].key == key
+}
+
+// merge walks the entries of a and b in key order. Containers whose key is present in
+// only one of the sets are kept as is when the corresponding keep flag is set, while
+// containers present in both are combined with fn.
+func merge(a, b Bitset, keepOnlyA, keepOnlyB bool, fn func(x, y *container) *container) Bitset {
+	var out Bitset
+	add := func(key uint16, c *container) {
+		if c != nil {
+			out.entries = append(out.entries, entry{key: key, container: c})
+			out.card += uint64(c.card)
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a.entries) || j < len(b.entries) {
+		switch {
+		case j == len(b.entries) || (i < len(a.entries) && a.entries[i].key < b.entries[j].key):
+			if keepOnlyA {
+				add(a.entries[i].key, a.entries[i].container)
+			}
+			i += 1
+		case i == len(a.entries) || b.entries[j].key < a.entries[i].key:
+			if keepOnlyB {
+				add(b.entries[j].key, b.entries[j].container)
+			}
+			j += 1
+		default:
+			add(a.entries[i].key, fn(a.entries[i].container, b.entries[j].container))
+			i += 1
+			j += 1
+		}
+	}
+	return out
+}
@@ -0,0 +1,189 @@
+package immutablebitset
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// sortedKeys returns the members of a map based set in ascending order.
+func sortedKeys(m map[uint32]struct{}) []uint32 {
+	if len(m) == 0 {
+		return nil
+	}
+
+	out := make([]uint32, 0, len(m))
+	for v := range m {
+		out = append(out, v)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+	return out
+}
+
+// randomSet creates a set along with an equivalent map. Values are concentrated in a
+// few containers so that both sparse and dense containers are exercised.
+func randomSet(r *rand.Rand, n int) (Bitset, map[uint32]struct{}) {
+	var b Bitset
+	m := map[uint32]struct{}{}
+	for i := 0; i < n; i++ {
+		v := uint32(r.Intn(4))<<16 | uint32(r.Intn(1<<14))
+		if r.Intn(4) == 0 {
+			// occasionally spread values over the whole range
+			v = r.Uint32()
+		}
+		b = b.Add(v)
+		m[v] = struct{}{}
+	}
+	return b, m
+}
+
+func TestZeroValue(t *testing.T) {
+	var b Bitset
+
+	require.Equal(t, uint64(0), b.Cardinality())
+	require.False(t, b.Contains(1))
+	require.Nil(t, b.Slice())
+	require.Equal(t, uint64(0), b.Rank(100))
+
+	_, ok := b.Select(0)
+	require.False(t, ok)
+}
+
+func TestAddRemove(t *testing.T) {
+	b := New(1, 5, 1<<20, 3, 5)
+	require.Equal(t, uint64(4), b.Cardinality())
+	require.Equal(t, []uint32{1, 3, 5, 1 << 20}, b.Slice())
+
+	// adding an existing member returns the same set
+	require.Equal(t, b, b.Add(3))
+	// removing a missing value returns the same set
+	require.Equal(t, b, b.Remove(4))
+	require.Equal(t, b, b.Remove(1<<30))
+
+	removed := b.Remove(1 << 20).Remove(3)
+	require.Equal(t, []uint32{1, 5}, removed.Slice())
+	require.Equal(t, uint64(2), removed.Cardinality())
+	require.Len(t, removed.entries, 1)
+
+	// the original set is unaffected
+	require.Equal(t, []uint32{1, 3, 5, 1 << 20}, b.Slice())
+}
+
+func TestContainerConversion(t *testing.T) {
+	var b Bitset
+	for i := 0; i <= arrayMaxSize; i++ {
+		b = b.Add(uint32(i * 2))
+	}
+	require.NotNil(t, b.entries[0].container.bitmap, "dense container should be a bitmap")
+	require.Equal(t, uint64(arrayMaxSize+1), b.Cardinality())
+
+	sparse := b.Remove(0)
+	require.Nil(t, sparse.entries[0].container.bitmap, "sparse container should be an array")
+	require.Equal(t, uint64(arrayMaxSize), sparse.Cardinality())
+
+	// the original still uses its bitmap
+	require.True(t, b.Contains(0))
+	require.NotNil(t, b.entries[0].container.bitmap)
+}
+
+func TestRankSelect(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	b, m := randomSet(r, 20000)
+	expected := sortedKeys(m)
+
+	for i, v := range expected {
+		require.Equal(t, uint64(i+1), b.Rank(v))
+
+		selected, ok := b.Select(uint64(i))
+		require.True(t, ok)
+		require.Equal(t, v, selected)
+	}
+
+	_, ok := b.Select(uint64(len(expected)))
+	require.False(t, ok)
+	require.Equal(t, uint64(len(expected)), b.Rank(^uint32(0)))
+}
+
+func TestSetOperations(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+
+	type operation struct {
+		apply    func(a, b Bitset) Bitset
+		includes func(inA, inB bool) bool
+	}
+
+	operations := map[string]operation{
+		"And": {
+			apply:    Bitset.And,
+			includes: func(inA, inB bool) bool { return inA && inB },
+		},
+		"AndNot": {
+			apply:    Bitset.AndNot,
+			includes: func(inA, inB bool) bool { return inA && !inB },
+		},
+		"Or": {
+			apply:    Bitset.Or,
+			includes: func(inA, inB bool) bool { return inA || inB },
+		},
+		"Xor": {
+			apply:    Bitset.Xor,
+			includes: func(inA, inB bool) bool { return inA != inB },
+		},
+	}
+
+	for _, size := range []int{0, 10, 3000, 20000} {
+		a, ma := randomSet(r, size)
+		b, mb := randomSet(r, size/2+7)
+
+		for name, op := range operations {
+			expected := map[uint32]struct{}{}
+			for v := range ma {
+				_, inB := mb[v]
+				if op.includes(true, inB) {
+					expected[v] = struct{}{}
+				}
+			}
+			for v := range mb {
+				_, inA := ma[v]
+				if op.includes(inA, true) {
+					expected[v] = struct{}{}
+				}
+			}
+
+			actual := op.apply(a, b)
+			require.Equal(t, sortedKeys(expected), actual.Slice(), "%s with size %d", name, size)
+			require.Equal(t, uint64(len(expected)), actual.Cardinality())
+
+			// the operands are unaffected
+			require.Equal(t, sortedKeys(ma), a.Slice())
+			require.Equal(t, sortedKeys(mb), b.Slice())
+		}
+	}
+}
+
+func TestSharedContainers(t *testing.T) {
+	a := New(1, 2, 1<<16|1)
+	b := New(2<<16 | 5)
+
+	union := a.Or(b)
+	require.Same(t, a.entries[0].container, union.entries[0].container)
+	require.Same(t, b.entries[0].container, union.entries[2].container)
+
+	// combining a set with itself reuses every container
+	self := a.And(a)
+	require.Same(t, a.entries[0].container, self.entries[0].container)
+	require.Equal(t, uint64(0), a.Xor(a).Cardinality())
+}
+
+func TestRange(t *testing.T) {
+	b := New(10, 1<<17, 3, 1<<25)
+
+	var firstThree []uint32
+	b.Range(func(v uint32) bool {
+		firstThree = append(firstThree, v)
+		return len(firstThree) < 3
+	})
+	require.Equal(t, []uint32{3, 10, 1 << 17}, firstThree)
+}
@@ -0,0 +1,238 @@
+package immutablebitset
+
+import (
+	"math/bits"
+	"sort"
+)
+
+const (
+	// arrayMaxSize is the maximum number of values stored in an array container. Above
+	// this size a bitmap container, which always uses 8KiB, is more compact.
+	arrayMaxSize = 4096
+
+	bitmapWords = 1 << 16 / 64
+)
+
+// container holds the low 16 bits of all values of a bitset that share the same high
+// 16 bits. Sparse containers store their values as a sorted array while dense containers
+// use a bitmap. Containers are never modified after they are created which allows them
+// to be shared between bitsets.
+type container struct {
+	array  []uint16
+	bitmap *[bitmapWords]uint64
+	card   int
+}
+
+// newArrayContainer creates a container holding the sorted values, converting to a
+// bitmap container when there are too many values. It returns nil for empty arrays.
+func newArrayContainer(values []uint16) *container {
+	if len(values) == 0 {
+		return nil
+	}
+	if len(values) > arrayMaxSize {
+		bm := new([bitmapWords]uint64)
+		for _, v := range values {
+			bm[v/64] |= 1 << (v % 64)
+		}
+		return &container{bitmap: bm, card: len(values)}
+	}
+	return &container{array: values, card: len(values)}
+}
+
+// newBitmapContainer creates a container holding the values set within the bitmap,
+// converting to an array container when there are few enough values. It returns nil
+// when no values are set.
+func newBitmapContainer(bm *[bitmapWords]uint64) *container {
+	card := 0
+	for _, w := range bm {
+		card += bits.OnesCount64(w)
+	}
+
+	switch {
+	case card == 0:
+		return nil
+	case card <= arrayMaxSize:
+		values := make([]uint16, 0, card)
+		forEachBit(bm, func(v uint16) bool {
+			values = append(values, v)
+			return true
+		})
+		return &container{array: values, card: card}
+	default:
+		return &container{bitmap: bm, card: card}
+	}
+}
+
+// toBitmap returns the values of the container as a bitmap. The result must not be
+// modified if the container is already a bitmap container.
+func (c *container) toBitmap() *[bitmapWords]uint64 {
+	if c.bitmap != nil {
+		return c.bitmap
+	}
+
+	bm := new([bitmapWords]uint64)
+	for _, v := range c.array {
+		bm[v/64] |= 1 << (v % 64)
+	}
+	return bm
+}
+
+func (c *container) contains(v uint16) bool {
+	if c.bitmap != nil {
+		return c.bitmap[v/64]&(1<<(v%64)) != 0
+	}
+
+	idx := sort.Search(len(c.array), func(i int) bool { return c.array[i] >= v })
+	return idx < len(c.array) && c.array[idx] == v
+}
+
+// add returns a container holding the values of c along with v. If v is already
+// present, c itself is returned.
+func (c *container) add(v uint16) *container {
+	if c.contains(v) {
+		return c
+	}
+
+	if c.bitmap != nil {
+		bm := *c.bitmap
+		bm[v/64] |= 1 << (v % 64)
+		return &container{bitmap: &bm, card: c.card + 1}
+	}
+
+	idx := sort.Search(len(c.array), func(i int) bool { return c.array[i] >= v })
+	values := make([]uint16, len(c.array)+1)
+	copy(values, c.array[:idx])
+	values[idx] = v
+	copy(values[idx+1:], c.array[idx:])
+	return newArrayContainer(values)
+}
+
+// remove returns a container holding the values of c without v. If v is not present,
+// c itself is returned. The result is nil if the container would be empty.
+func (c *container) remove(v uint16) *container {
+	if !c.contains(v) {
+		return c
+	}
+
+	if c.bitmap != nil {
+		bm := *c.bitmap
+		bm[v/64] &^= 1 << (v % 64)
+		return newBitmapContainer(&bm)
+	}
+
+	idx := sort.Search(len(c.array), func(i int) bool { return c.array[i] >= v })
+	values := make([]uint16, 0, len(c.array)-1)
+	values = append(values, c.array[:idx]...)
+	values = append(values, c.array[idx+1:]...)
+	return newArrayContainer(values)
+}
+
+// rank returns the number of values within the container that are less than or equal
+// to v.
+func (c *container) rank(v uint16) int {
+	if c.bitmap == nil {
+		return sort.Search(len(c.array), func(i int) bool { return c.array[i] > v })
+	}
+
+	word := int(v / 64)
+	n := 0
+	for _, w := range c.bitmap[:word] {
+		n += bits.OnesCount64(w)
+	}
+	// shifting by 64 results in 0 so the mask covers the full word when v%64 == 63
+	mask := uint64(1)<<(v%64+1) - 1
+	return n + bits.OnesCount64(c.bitmap[word]&mask)
+}
+
+// selectAt returns the i-th smallest value within the container.
+func (c *container) selectAt(i int) uint16 {
+	if c.bitmap == nil {
+		return c.array[i]
+	}
+
+	for idx, w := range c.bitmap {
+		n := bits.OnesCount64(w)
+		if i >= n {
+			i -= n
+			continue
+		}
+
+		for ; i > 0; i-- {
+			// clear the lowest set bit
+			w &= w - 1
+		}
+		return uint16(idx*64 + bits.TrailingZeros64(w))
+	}
+	panic("immutablebitset: select out of range")
+}
+
+// forEach calls fn for each value of the container in ascending order and reports
+// whether iteration completed without fn returning false.
+func (c *container) forEach(fn func(uint16) bool) bool {
+	if c.bitmap != nil {
+		return forEachBit(c.bitmap, fn)
+	}
+
+	for _, v := range c.array {
+		if !fn(v) {
+			return false
+		}
+	}
+	return true
+}
+
+func forEachBit(bm *[bitmapWords]uint64, fn func(uint16) bool) bool {
+	for idx, w := range bm {
+		for w != 0 {
+			v := uint16(idx*64 + bits.TrailingZeros64(w))
+			if !fn(v) {
+				return false
+			}
+			w &= w - 1
+		}
+	}
+	return true
+}
+
+// combine computes a set operation between two containers. The op function performs
+// the operation on 64 values at a time and is applied word by word when either of the
+// containers is a bitmap. Array containers are merged directly, applying op to single
+// bits to decide which values are part of the result.
+func combine(a, b *container, op func(x, y uint64) uint64) *container {
+	if a.bitmap == nil && b.bitmap == nil {
+		keepA := op(1, 0) == 1
+		keepB := op(0, 1) == 1
+		keepBoth := op(1, 1) == 1
+
+		values := make([]uint16, 0, len(a.array)+len(b.array))
+		i, j := 0, 0
+		for i < len(a.array) || j < len(b.array) {
+			switch {
+			case j == len(b.array) || (i < len(a.array) && a.array[i] < b.array[j]):
+				if keepA {
+					values = append(values, a.array[i])
+				}
+				i += 1
+			case i == len(a.array) || b.array[j] < a.array[i]:
+				if keepB {
+					values = append(values, b.array[j])
+				}
+				j += 1
+			default:
+				if keepBoth {
+					values = append(values, a.array[i])
+				}
+				i += 1
+				j += 1
+			}
+		}
+		return newArrayContainer(values)
+	}
+
+	abm, bbm := a.toBitmap(), b.toBitmap()
+	bm := new([bitmapWords]uint64)
+	for i := range bm {
+		bm[i] = op(abm[i], bbm[i])
+	}
+	return newBitmapContainer(bm)
+}
@@ -0,0 +1,237 @@
+// Package immutablebytes provides variants of the functions in the standard libraries
+// bytes package which never return slices aliasing their inputs. Many bytes functions,
+// such as bytes.TrimSpace or bytes.Fields, return sub-slices of their input which means
+// that later modifications of the input buffer, for example when it is reused, will be
+// visible through the results. Every function in this package returns results backed
+// by freshly allocated arrays instead.
+//
+// Just like the functions of the immutableslice package, empty results are always
+// returned as nil slices as they have no backing array that could be shared.
+package immutablebytes
+
+import (
+	"bytes"
+	"unsafe"
+)
+
+// Clone returns a copy of s with a freshly allocated backing array. Unlike bytes.Clone,
+// an empty but non-nil input results in a nil slice.
+func Clone(s []byte) []byte {
+	if len(s) == 0 {
+		return nil
+	}
+	return bytes.Clone(s)
+}
+
+// Cut is an immutable variant of the standard libraries bytes.Cut function. It slices s
+// around the first instance of sep returning copies of the text before and after sep.
+// If sep does not appear in s, Cut returns a copy of s, nil and false.
+func Cut(s, sep []byte) (before, after []byte, found bool) {
+	before, after, found = bytes.Cut(s, sep)
+	parts := cloneAll([][]byte{before, after})
+	return parts[0], parts[1], found
+}
+
+// CutPrefix is an immutable variant of the standard libraries bytes.CutPrefix function.
+// It returns a copy of s without the provided leading prefix and reports whether the
+// prefix was found.
+func CutPrefix(s, prefix []byte) (after []byte, found bool) {
+	after, found = bytes.CutPrefix(s, prefix)
+	return Clone(after), found
+}
+
+// CutSuffix is an immutable variant of the standard libraries bytes.CutSuffix function.
+// It returns a copy of s without the provided ending suffix and reports whether the
+// suffix was found.
+func CutSuffix(s, suffix []byte) (before []byte, found bool) {
+	before, found = bytes.CutSuffix(s, suffix)
+	return Clone(before), found
+}
+
+// Fields is an immutable variant of the standard libraries bytes.Fields function. It
+// splits s around each run of white space characters and returns copies of the fields.
+func Fields(s []byte) [][]byte {
+	return cloneAll(bytes.Fields(s))
+}
+
+// FieldsFunc is an immutable variant of the standard libraries bytes.FieldsFunc function.
+// It splits s at each run of code points satisfying f and returns copies of the fields.
+func FieldsFunc(s []byte, f func(rune) bool) [][]byte {
+	return cloneAll(bytes.FieldsFunc(s, f))
+}
+
+// Join is an immutable variant of the standard libraries bytes.Join function. It
+// concatenates the elements of s with sep placed between them into a new slice.
+func Join(s [][]byte, sep []byte) []byte {
+	return nilIfEmpty(bytes.Join(s, sep))
+}
+
+// JoinString behaves like Join but returns the result as a string. As the joined bytes
+// are written to a buffer that is never shared, the conversion is performed without
+// copying the bytes a second time.
+func JoinString(s [][]byte, sep []byte) string {
+	return unsafeString(bytes.Join(s, sep))
+}
+
+// Repeat is an immutable variant of the standard libraries bytes.Repeat function. It
+// returns a new slice consisting of count copies of s.
+func Repeat(s []byte, count int) []byte {
+	return nilIfEmpty(bytes.Repeat(s, count))
+}
+
+// RepeatString behaves like Repeat but returns the result as a string without copying
+// the repeated bytes a second time.
+func RepeatString(s []byte, count int) string {
+	return unsafeString(bytes.Repeat(s, count))
+}
+
+// Replace is an immutable variant of the standard libraries bytes.Replace function. It
+// returns a copy of s with the first n non-overlapping instances of old replaced by new.
+// If n < 0, there is no limit on the number of replacements.
+func Replace(s, old, new []byte, n int) []byte {
+	return nilIfEmpty(bytes.Replace(s, old, new, n))
+}
+
+// ReplaceAll is an immutable variant of the standard libraries bytes.ReplaceAll function.
+// It returns a copy of s with all non-overlapping instances of old replaced by new.
+func ReplaceAll(s, old, new []byte) []byte {
+	return nilIfEmpty(bytes.ReplaceAll(s, old, new))
+}
+
+// ReplaceAllString behaves like ReplaceAll but returns the result as a string without
+// copying the replaced bytes a second time.
+func ReplaceAllString(s, old, new []byte) string {
+	return unsafeString(bytes.ReplaceAll(s, old, new))
+}
+
+// Split is an immutable variant of the standard libraries bytes.Split function. It
+// slices s into all subslices separated by sep and returns copies of them. Empty
+// subslices are represented as nil.
+func Split(s, sep []byte) [][]byte {
+	return cloneAll(bytes.Split(s, sep))
+}
+
+// SplitAfter is an immutable variant of the standard libraries bytes.SplitAfter function.
+// It slices s into all subslices after each instance of sep and returns copies of them.
+func SplitAfter(s, sep []byte) [][]byte {
+	return cloneAll(bytes.SplitAfter(s, sep))
+}
+
+// SplitN is an immutable variant of the standard libraries bytes.SplitN function. It
+// slices s into at most n subslices separated by sep and returns copies of them.
+func SplitN(s, sep []byte, n int) [][]byte {
+	return cloneAll(bytes.SplitN(s, sep, n))
+}
+
+// ToLower is an immutable variant of the standard libraries bytes.ToLower function. It
+// returns a copy of s with all Unicode letters mapped to their lower case.
+func ToLower(s []byte) []byte {
+	return nilIfEmpty(bytes.ToLower(s))
+}
+
+// ToLowerString behaves like ToLower but returns the result as a string without copying
+// the mapped bytes a second time.
+func ToLowerString(s []byte) string {
+	return unsafeString(bytes.ToLower(s))
+}
+
+// ToUpper is an immutable variant of the standard libraries bytes.ToUpper function. It
+// returns a copy of s with all Unicode letters mapped to their upper case.
+func ToUpper(s []byte) []byte {
+	return nilIfEmpty(bytes.ToUpper(s))
+}
+
+// ToUpperString behaves like ToUpper but returns the result as a string without copying
+// the mapped bytes a second time.
+func ToUpperString(s []byte) string {
+	return unsafeString(bytes.ToUpper(s))
+}
+
+// Trim is an immutable variant of the standard libraries bytes.Trim function. It returns
+// a copy of s with all leading and trailing code points contained in cutset removed.
+func Trim(s []byte, cutset string) []byte {
+	return Clone(bytes.Trim(s, cutset))
+}
+
+// TrimFunc is an immutable variant of the standard libraries bytes.TrimFunc function. It
+// returns a copy of s with all leading and trailing code points satisfying f removed.
+func TrimFunc(s []byte, f func(rune) bool) []byte {
+	return Clone(bytes.TrimFunc(s, f))
+}
+
+// TrimLeft is an immutable variant of the standard libraries bytes.TrimLeft function. It
+// returns a copy of s with all leading code points contained in cutset removed.
+func TrimLeft(s []byte, cutset string) []byte {
+	return Clone(bytes.TrimLeft(s, cutset))
+}
+
+// TrimPrefix is an immutable variant of the standard libraries bytes.TrimPrefix function.
+// It returns a copy of s without the provided leading prefix.
+func TrimPrefix(s, prefix []byte) []byte {
+	return Clone(bytes.TrimPrefix(s, prefix))
+}
+
+// TrimRight is an immutable variant of the standard libraries bytes.TrimRight function.
+// It returns a copy of s with all trailing code points contained in cutset removed.
+func TrimRight(s []byte, cutset string) []byte {
+	return Clone(bytes.TrimRight(s, cutset))
+}
+
+// TrimSpace is an immutable variant of the standard libraries bytes.TrimSpace function.
+// It returns a copy of s with all leading and trailing white space removed.
+func TrimSpace(s []byte) []byte {
+	return Clone(bytes.TrimSpace(s))
+}
+
+// TrimSuffix is an immutable variant of the standard libraries bytes.TrimSuffix function.
+// It returns a copy of s without the provided trailing suffix.
+func TrimSuffix(s, suffix []byte) []byte {
+	return Clone(bytes.TrimSuffix(s, suffix))
+}
+
+// cloneAll copies each of the parts into a single newly allocated backing array. Each
+// returned part has its capacity limited to its length so that appending to one part
+// will reallocate rather than overwrite the part following it.
+func cloneAll(parts [][]byte) [][]byte {
+	if len(parts) == 0 {
+		return nil
+	}
+
+	size := 0
+	for _, p := range parts {
+		size += len(p)
+	}
+
+	buf := make([]byte, size)
+	out := make([][]byte, len(parts))
+	offset := 0
+	for i, p := range parts {
+		if len(p) == 0 {
+			continue
+		}
+		n := copy(buf[offset:], p)
+		out[i] = buf[offset : offset+n : offset+n]
+		offset += n
+	}
+	return out
+}
+
+// nilIfEmpty is used with the results of bytes functions which are documented to always
+// return a newly allocated slice. Those results are returned as is, without copying them
+// again, apart from normalizing empty results to nil.
+func nilIfEmpty(b []byte) []byte {
+	if len(b) == 0 {
+		return nil
+	}
+	return b
+}
+
+// unsafeString converts b to a string without copying it. This must only be used with
+// slices whose backing array was freshly allocated by the caller and is never accessed
+// again after the conversion. Otherwise the immutability of the string would be violated.
+func unsafeString(b []byte) string {
+	if len(b) == 0 {
+		return ""
+	}
+	return unsafe.String(unsafe.SliceData(b), len(b))
+}
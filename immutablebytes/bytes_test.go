@@ -0,0 +1,327 @@
+package immutablebytes
+
+import (
+	"bytes"
+	"testing"
+	"unicode"
+
+	"github.com/stretchr/testify/require"
+)
+
+// overwrite replaces every byte of s so that any result aliasing it would change.
+func overwrite(s []byte) {
+	for i := range s {
+		s[i] = '#'
+	}
+}
+
+func TestSingleResult(t *testing.T) {
+	type testCase struct {
+		input    string
+		fn       func([]byte) []byte
+		expected []byte
+	}
+
+	cases := map[string]testCase{
+		"Clone": {
+			input:    "hello",
+			fn:       Clone,
+			expected: []byte("hello"),
+		},
+		"Clone empty": {
+			input:    "",
+			fn:       Clone,
+			expected: nil,
+		},
+		"CutPrefix": {
+			input: "prefix-value",
+			fn: func(s []byte) []byte {
+				after, _ := CutPrefix(s, []byte("prefix-"))
+				return after
+			},
+			expected: []byte("value"),
+		},
+		"CutSuffix": {
+			input: "value-suffix",
+			fn: func(s []byte) []byte {
+				before, _ := CutSuffix(s, []byte("-suffix"))
+				return before
+			},
+			expected: []byte("value"),
+		},
+		"Join": {
+			input: "a,b,c",
+			fn: func(s []byte) []byte {
+				return Join([][]byte{s[0:1], s[2:3], s[4:5]}, []byte("+"))
+			},
+			expected: []byte("a+b+c"),
+		},
+		"Join single element": {
+			input: "abc",
+			fn: func(s []byte) []byte {
+				return Join([][]byte{s}, []byte("+"))
+			},
+			expected: []byte("abc"),
+		},
+		"Repeat": {
+			input:    "ab",
+			fn:       func(s []byte) []byte { return Repeat(s, 3) },
+			expected: []byte("ababab"),
+		},
+		"Replace": {
+			input:    "aaaa",
+			fn:       func(s []byte) []byte { return Replace(s, []byte("a"), []byte("b"), 2) },
+			expected: []byte("bbaa"),
+		},
+		"ReplaceAll": {
+			input:    "hello world",
+			fn:       func(s []byte) []byte { return ReplaceAll(s, []byte("o"), []byte("0")) },
+			expected: []byte("hell0 w0rld"),
+		},
+		"ReplaceAll no match": {
+			input:    "hello",
+			fn:       func(s []byte) []byte { return ReplaceAll(s, []byte("x"), []byte("y")) },
+			expected: []byte("hello"),
+		},
+		"ToLower": {
+			input:    "HeLLo",
+			fn:       ToLower,
+			expected: []byte("hello"),
+		},
+		"ToLower already lower": {
+			input:    "hello",
+			fn:       ToLower,
+			expected: []byte("hello"),
+		},
+		"ToUpper": {
+			input:    "HeLLo",
+			fn:       ToUpper,
+			expected: []byte("HELLO"),
+		},
+		"ToUpper empty": {
+			input:    "",
+			fn:       ToUpper,
+			expected: nil,
+		},
+		"Trim": {
+			input:    "xxhelloxx",
+			fn:       func(s []byte) []byte { return Trim(s, "x") },
+			expected: []byte("hello"),
+		},
+		"Trim everything": {
+			input:    "xxxx",
+			fn:       func(s []byte) []byte { return Trim(s, "x") },
+			expected: nil,
+		},
+		"TrimFunc": {
+			input:    "123hello456",
+			fn:       func(s []byte) []byte { return TrimFunc(s, unicode.IsDigit) },
+			expected: []byte("hello"),
+		},
+		"TrimLeft": {
+			input:    "xxhelloxx",
+			fn:       func(s []byte) []byte { return TrimLeft(s, "x") },
+			expected: []byte("helloxx"),
+		},
+		"TrimPrefix": {
+			input:    "prefix-value",
+			fn:       func(s []byte) []byte { return TrimPrefix(s, []byte("prefix-")) },
+			expected: []byte("value"),
+		},
+		"TrimRight": {
+			input:    "xxhelloxx",
+			fn:       func(s []byte) []byte { return TrimRight(s, "x") },
+			expected: []byte("xxhello"),
+		},
+		"TrimSpace": {
+			input:    "  \thello\n ",
+			fn:       TrimSpace,
+			expected: []byte("hello"),
+		},
+		"TrimSpace only space": {
+			input:    " \t\n",
+			fn:       TrimSpace,
+			expected: nil,
+		},
+		"TrimSuffix": {
+			input:    "value-suffix",
+			fn:       func(s []byte) []byte { return TrimSuffix(s, []byte("-suffix")) },
+			expected: []byte("value"),
+		},
+	}
+
+	for name, tcase := range cases {
+		tcase := tcase
+
+		t.Run(name, func(t *testing.T) {
+			input := []byte(tcase.input)
+
+			actual := tcase.fn(input)
+			require.Equal(t, tcase.expected, actual)
+
+			// check that the result does not alias the input
+			overwrite(input)
+			require.Equal(t, tcase.expected, actual)
+		})
+	}
+}
+
+func TestMultipleResults(t *testing.T) {
+	type testCase struct {
+		input    string
+		fn       func([]byte) [][]byte
+		expected [][]byte
+	}
+
+	cases := map[string]testCase{
+		"Fields": {
+			input:    "  a bb  ccc ",
+			fn:       Fields,
+			expected: [][]byte{[]byte("a"), []byte("bb"), []byte("ccc")},
+		},
+		"Fields only space": {
+			input:    "   ",
+			fn:       Fields,
+			expected: nil,
+		},
+		"FieldsFunc": {
+			input: "a1bb22ccc",
+			fn: func(s []byte) [][]byte {
+				return FieldsFunc(s, unicode.IsDigit)
+			},
+			expected: [][]byte{[]byte("a"), []byte("bb"), []byte("ccc")},
+		},
+		"Split": {
+			input: "a,,b",
+			fn: func(s []byte) [][]byte {
+				return Split(s, []byte(","))
+			},
+			expected: [][]byte{[]byte("a"), nil, []byte("b")},
+		},
+		"SplitAfter": {
+			input: "a,b,c",
+			fn: func(s []byte) [][]byte {
+				return SplitAfter(s, []byte(","))
+			},
+			expected: [][]byte{[]byte("a,"), []byte("b,"), []byte("c")},
+		},
+		"SplitN": {
+			input: "a,b,c",
+			fn: func(s []byte) [][]byte {
+				return SplitN(s, []byte(","), 2)
+			},
+			expected: [][]byte{[]byte("a"), []byte("b,c")},
+		},
+		"SplitN zero": {
+			input: "a,b,c",
+			fn: func(s []byte) [][]byte {
+				return SplitN(s, []byte(","), 0)
+			},
+			expected: nil,
+		},
+		"Cut": {
+			input: "key=value",
+			fn: func(s []byte) [][]byte {
+				before, after, found := Cut(s, []byte("="))
+				require.True(t, found)
+				return [][]byte{before, after}
+			},
+			expected: [][]byte{[]byte("key"), []byte("value")},
+		},
+		"Cut not found": {
+			input: "key",
+			fn: func(s []byte) [][]byte {
+				before, after, found := Cut(s, []byte("="))
+				require.False(t, found)
+				return [][]byte{before, after}
+			},
+			expected: [][]byte{[]byte("key"), nil},
+		},
+	}
+
+	for name, tcase := range cases {
+		tcase := tcase
+
+		t.Run(name, func(t *testing.T) {
+			input := []byte(tcase.input)
+
+			actual := tcase.fn(input)
+			require.Equal(t, tcase.expected, actual)
+
+			// check that the results do not alias the input
+			overwrite(input)
+			require.Equal(t, tcase.expected, actual)
+
+			// appending to one part must not overwrite the following parts
+			if len(actual) > 1 {
+				_ = append(actual[0], bytes.Repeat([]byte("!"), 10)...)
+				require.Equal(t, tcase.expected, actual)
+			}
+		})
+	}
+}
+
+func TestStringResults(t *testing.T) {
+	type testCase struct {
+		input    string
+		fn       func([]byte) string
+		expected string
+	}
+
+	cases := map[string]testCase{
+		"JoinString": {
+			input: "a,b",
+			fn: func(s []byte) string {
+				return JoinString([][]byte{s[0:1], s[2:3]}, []byte("-"))
+			},
+			expected: "a-b",
+		},
+		"JoinString single element": {
+			input: "abc",
+			fn: func(s []byte) string {
+				return JoinString([][]byte{s}, nil)
+			},
+			expected: "abc",
+		},
+		"RepeatString": {
+			input:    "ab",
+			fn:       func(s []byte) string { return RepeatString(s, 2) },
+			expected: "abab",
+		},
+		"ReplaceAllString": {
+			input:    "hello",
+			fn:       func(s []byte) string { return ReplaceAllString(s, []byte("l"), []byte("L")) },
+			expected: "heLLo",
+		},
+		"ToLowerString": {
+			input:    "HeLLo",
+			fn:       ToLowerString,
+			expected: "hello",
+		},
+		"ToUpperString": {
+			input:    "hello",
+			fn:       ToUpperString,
+			expected: "HELLO",
+		},
+		"ToUpperString empty": {
+			input:    "",
+			fn:       ToUpperString,
+			expected: "",
+		},
+	}
+
+	for name, tcase := range cases {
+		tcase := tcase
+
+		t.Run(name, func(t *testing.T) {
+			input := []byte(tcase.input)
+
+			actual := tcase.fn(input)
+			require.Equal(t, tcase.expected, actual)
+
+			// the string must not share memory with the input
+			overwrite(input)
+			require.Equal(t, tcase.expected, actual)
+		})
+	}
+}
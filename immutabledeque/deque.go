@@ -0,0 +1,249 @@
+// Package immutabledeque provides a persistent double-ended queue. Every operation
+// returns a new Deque and leaves the original untouched, with the new version sharing
+// as much of its internal structure with the old one as possible.
+package immutabledeque
+
+// balanceFactor is the maximum allowed ratio between the lengths of the front and rear
+// lists before the deque is rebalanced.
+const balanceFactor = 3
+
+// node is a single cell of an immutable singly linked list. Nodes are never modified
+// after they are created which allows them to be freely shared between deques.
+type node[E any] struct {
+	value E
+	next  *node[E]
+}
+
+// Deque is a persistent double-ended queue implemented as a banker's deque. Elements
+// are stored in two immutable linked lists, one holding the front of the deque and the
+// other holding the rear in reverse order. Whenever one list grows too large relative
+// to the other, the elements are redistributed so that both ends stay cheap to access.
+//
+// Pushing and popping at either end runs in O(1) amortized time. The amortized bounds
+// hold for any sequence of operations applied to the latest version; repeatedly
+// operating on the same old version may trigger the same rebalance more than once.
+//
+// The zero value of a Deque is an empty deque ready to use. A Deque is safe for
+// concurrent use by multiple goroutines as none of its methods modify it.
+type Deque[E any] struct {
+	front    *node[E]
+	frontLen int
+	rear     *node[E]
+	rearLen  int
+}
+
+// New creates a deque containing the specified elements with the first element at
+// the front of the deque and the last element at the back.
+func New[E any](elems ...E) Deque[E] {
+	var d Deque[E]
+	for _, v := range elems {
+		d = d.PushBack(v)
+	}
+	return d
+}
+
+// Len returns the number of elements in the deque.
+func (d Deque[E]) Len() int {
+	return d.frontLen + d.rearLen
+}
+
+// PushFront returns a new deque with v added to the front of d.
+func (d Deque[E]) PushFront(v E) Deque[E] {
+	d.front = &node[E]{value: v, next: d.front}
+	d.frontLen += 1
+	return d.balance()
+}
+
+// PushBack returns a new deque with v added to the back of d.
+func (d Deque[E]) PushBack(v E) Deque[E] {
+	d.rear = &node[E]{value: v, next: d.rear}
+	d.rearLen += 1
+	return d.balance()
+}
+
+// PeekFront returns the element at the front of the deque. The boolean return value
+// will be false if the deque is empty.
+func (d Deque[E]) PeekFront() (E, bool) {
+	if d.front != nil {
+		return d.front.value, true
+	}
+
+	// The balance invariant guarantees that when the front list is empty the
+	// rear list holds at most a single element.
+	if d.rear != nil {
+		return d.rear.value, true
+	}
+
+	var zero E
+	return zero, false
+}
+
+// PeekBack returns the element at the back of the deque. The boolean return value
+// will be false if the deque is empty.
+func (d Deque[E]) PeekBack() (E, bool) {
+	if d.rear != nil {
+		return d.rear.value, true
+	}
+
+	// The balance invariant guarantees that when the rear list is empty the
+	// front list holds at most a single element.
+	if d.front != nil {
+		return d.front.value, true
+	}
+
+	var zero E
+	return zero, false
+}
+
+// PopFront returns the element at the front of the deque along with a new deque that
+// contains the remaining elements. The boolean return value will be false if the deque
+// is empty in which case the returned deque is also empty.
+func (d Deque[E]) PopFront() (E, Deque[E], bool) {
+	if d.front == nil {
+		if d.rear == nil {
+			var zero E
+			return zero, d, false
+		}
+
+		// a single element stored in the rear list
+		return d.rear.value, Deque[E]{}, true
+	}
+
+	v := d.front.value
+	d.front = d.front.next
+	d.frontLen -= 1
+	return v, d.balance(), true
+}
+
+// PopBack returns the element at the back of the deque along with a new deque that
+// contains the remaining elements. The boolean return value will be false if the deque
+// is empty in which case the returned deque is also empty.
+func (d Deque[E]) PopBack() (E, Deque[E], bool) {
+	if d.rear == nil {
+		if d.front == nil {
+			var zero E
+			return zero, d, false
+		}
+
+		// a single element stored in the front list
+		return d.front.value, Deque[E]{}, true
+	}
+
+	v := d.rear.value
+	d.rear = d.rear.next
+	d.rearLen -= 1
+	return v, d.balance(), true
+}
+
+// Range calls fn for each element of the deque in order from front to back. Iteration
+// stops early if fn returns false.
+func (d Deque[E]) Range(fn func(E) bool) {
+	for n := d.front; n != nil; n = n.next {
+		if !fn(n.value) {
+			return
+		}
+	}
+
+	// The rear list is stored in reverse order. Walking it backwards would require
+	// either recursion or a temporary buffer so we materialize it into a slice.
+	if d.rear == nil {
+		return
+	}
+
+	rear := make([]E, d.rearLen)
+	i := d.rearLen - 1
+	for n := d.rear; n != nil; n = n.next {
+		rear[i] = n.value
+		i -= 1
+	}
+
+	for _, v := range rear {
+		if !fn(v) {
+			return
+		}
+	}
+}
+
+// Slice returns a newly allocated slice holding the elements of the deque in order from
+// front to back. Just like the functions of the immutableslice package, an empty deque
+// results in a nil slice.
+func (d Deque[E]) Slice() []E {
+	if d.Len() == 0 {
+		return nil
+	}
+
+	s := make([]E, 0, d.Len())
+	d.Range(func(v E) bool {
+		s = append(s, v)
+		return true
+	})
+	return s
+}
+
+// balance redistributes the elements between the front and rear lists when one of them
+// has grown more than balanceFactor times larger than the other.
+func (d Deque[E]) balance() Deque[E] {
+	switch {
+	case d.frontLen > balanceFactor*d.rearLen+1:
+		keep := (d.frontLen + d.rearLen) / 2
+		front, moved := split(d.front, keep)
+		d.rear = appendReversed(d.rear, moved)
+		d.front = front
+		d.rearLen += d.frontLen - keep
+		d.frontLen = keep
+	case d.rearLen > balanceFactor*d.frontLen+1:
+		keep := (d.frontLen + d.rearLen) / 2
+		rear, moved := split(d.rear, keep)
+		d.front = appendReversed(d.front, moved)
+		d.rear = rear
+		d.frontLen += d.rearLen - keep
+		d.rearLen = keep
+	}
+	return d
+}
+
+// split returns a copy of the first n nodes of l along with the remaining nodes which
+// are shared with l.
+func split[E any](l *node[E], n int) (*node[E], *node[E]) {
+	var head, tail *node[E]
+	for i := 0; i < n; i++ {
+		c := &node[E]{value: l.value}
+		if tail == nil {
+			head = c
+		} else {
+			tail.next = c
+		}
+		tail = c
+		l = l.next
+	}
+	return head, l
+}
+
+// appendReversed returns a list with the nodes of l followed by the nodes of other in
+// reverse order. The nodes of l are copied while the new tail is freshly allocated.
+func appendReversed[E any](l, other *node[E]) *node[E] {
+	var rev *node[E]
+	for n := other; n != nil; n = n.next {
+		rev = &node[E]{value: n.value, next: rev}
+	}
+
+	if l == nil {
+		return rev
+	}
+
+	head, tail := copyList(l)
+	tail.next = rev
+	return head
+}
+
+// copyList creates a copy of all the nodes of the non-empty list l and returns the first
+// and last nodes of the copy.
+func copyList[E any](l *node[E]) (*node[E], *node[E]) {
+	head := &node[E]{value: l.value}
+	tail := head
+	for n := l.next; n != nil; n = n.next {
+		tail.next = &node[E]{value: n.value}
+		tail = tail.next
+	}
+	return head, tail
+}
@@ -0,0 +1,191 @@
+package immutabledeque
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	type testCase struct {
+		elems    []int
+		expected []int
+	}
+
+	cases := map[string]testCase{
+		"no elements": {
+			elems:    nil,
+			expected: nil,
+		},
+		"single element": {
+			elems:    []int{1},
+			expected: []int{1},
+		},
+		"many elements": {
+			elems:    []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10},
+			expected: []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10},
+		},
+	}
+
+	for name, tcase := range cases {
+		tcase := tcase
+
+		t.Run(name, func(t *testing.T) {
+			d := New(tcase.elems...)
+			require.Equal(t, len(tcase.expected), d.Len())
+			require.Equal(t, tcase.expected, d.Slice())
+		})
+	}
+}
+
+func TestZeroValue(t *testing.T) {
+	var d Deque[int]
+
+	require.Equal(t, 0, d.Len())
+	require.Nil(t, d.Slice())
+
+	_, ok := d.PeekFront()
+	require.False(t, ok)
+	_, ok = d.PeekBack()
+	require.False(t, ok)
+
+	_, popped, ok := d.PopFront()
+	require.False(t, ok)
+	require.Equal(t, 0, popped.Len())
+
+	_, popped, ok = d.PopBack()
+	require.False(t, ok)
+	require.Equal(t, 0, popped.Len())
+}
+
+func TestPush(t *testing.T) {
+	var d Deque[int]
+
+	d1 := d.PushBack(2)
+	d2 := d1.PushFront(1)
+	d3 := d2.PushBack(3)
+
+	require.Equal(t, []int{2}, d1.Slice())
+	require.Equal(t, []int{1, 2}, d2.Slice())
+	require.Equal(t, []int{1, 2, 3}, d3.Slice())
+
+	front, ok := d3.PeekFront()
+	require.True(t, ok)
+	require.Equal(t, 1, front)
+
+	back, ok := d3.PeekBack()
+	require.True(t, ok)
+	require.Equal(t, 3, back)
+
+	// pushing to an older version must not affect newer versions sharing its lists
+	d4 := d2.PushBack(42)
+	require.Equal(t, []int{1, 2, 42}, d4.Slice())
+	require.Equal(t, []int{1, 2, 3}, d3.Slice())
+}
+
+func TestPop(t *testing.T) {
+	type testCase struct {
+		build    func() Deque[int]
+		expected []int
+	}
+
+	cases := map[string]testCase{
+		"pushed to back": {
+			build: func() Deque[int] {
+				return New(1, 2, 3, 4, 5, 6, 7, 8)
+			},
+			expected: []int{1, 2, 3, 4, 5, 6, 7, 8},
+		},
+		"pushed to front": {
+			build: func() Deque[int] {
+				var d Deque[int]
+				for i := 8; i > 0; i-- {
+					d = d.PushFront(i)
+				}
+				return d
+			},
+			expected: []int{1, 2, 3, 4, 5, 6, 7, 8},
+		},
+		"pushed to both ends": {
+			build: func() Deque[int] {
+				return New(4, 5).PushFront(3).PushBack(6).PushFront(2).PushBack(7).PushFront(1).PushBack(8)
+			},
+			expected: []int{1, 2, 3, 4, 5, 6, 7, 8},
+		},
+	}
+
+	for name, tcase := range cases {
+		tcase := tcase
+
+		t.Run(name, func(t *testing.T) {
+			t.Run("PopFront", func(t *testing.T) {
+				d := tcase.build()
+				original := d.Slice()
+
+				var actual []int
+				for cur := d; cur.Len() > 0; {
+					peeked, ok := cur.PeekFront()
+					require.True(t, ok)
+
+					var v int
+					v, cur, ok = cur.PopFront()
+					require.True(t, ok)
+					require.Equal(t, peeked, v)
+					actual = append(actual, v)
+				}
+
+				require.Equal(t, tcase.expected, actual)
+				// popping must leave the original deque untouched
+				require.Equal(t, original, d.Slice())
+			})
+
+			t.Run("PopBack", func(t *testing.T) {
+				d := tcase.build()
+				original := d.Slice()
+
+				var actual []int
+				for cur := d; cur.Len() > 0; {
+					peeked, ok := cur.PeekBack()
+					require.True(t, ok)
+
+					var v int
+					v, cur, ok = cur.PopBack()
+					require.True(t, ok)
+					require.Equal(t, peeked, v)
+					actual = append([]int{v}, actual...)
+				}
+
+				require.Equal(t, tcase.expected, actual)
+				// popping must leave the original deque untouched
+				require.Equal(t, original, d.Slice())
+			})
+		})
+	}
+}
+
+func TestRange(t *testing.T) {
+	d := New(3, 4, 5).PushFront(2).PushFront(1)
+
+	var all []int
+	d.Range(func(v int) bool {
+		all = append(all, v)
+		return true
+	})
+	require.Equal(t, []int{1, 2, 3, 4, 5}, all)
+
+	var firstThree []int
+	d.Range(func(v int) bool {
+		firstThree = append(firstThree, v)
+		return len(firstThree) < 3
+	})
+	require.Equal(t, []int{1, 2, 3}, firstThree)
+}
+
+func TestSliceImmutability(t *testing.T) {
+	d := New(1, 2, 3)
+
+	s := d.Slice()
+	s[0] = 42
+
+	require.Equal(t, []int{1, 2, 3}, d.Slice())
+}
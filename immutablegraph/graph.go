@@ -0,0 +1,256 @@
+// Package immutablegraph provides a persistent directed graph. Every edit returns a new
+// graph which shares its unchanged adjacency information with the graph it was derived
+// from, so older snapshots of a graph remain valid and cheap to keep around.
+package immutablegraph
+
+import (
+	"cmp"
+	"errors"
+
+	"github.com/mkeeler/go-immutable/internal/ordmap"
+)
+
+// ErrCycle is returned by TopologicalSort when the graph contains a cycle.
+var ErrCycle = errors.New("immutablegraph: graph contains a cycle")
+
+// adjacency holds the sets of nodes directly connected to a node by outgoing and
+// incoming edges.
+type adjacency[N any] struct {
+	out ordmap.Map[N, struct{}]
+	in  ordmap.Map[N, struct{}]
+}
+
+// Graph is a persistent directed graph without parallel edges. Nodes are kept ordered by
+// a comparison function which makes all node listings as well as the topological sort
+// deterministic. The adjacency sets of each node are persistent maps, so adding or
+// removing an edge runs in O(log n) time and only copies the affected paths.
+//
+// Graphs must be created with New or NewFunc. A Graph is safe for concurrent use by
+// multiple goroutines as none of its methods modify it.
+type Graph[N any] struct {
+	nodes ordmap.Map[N, adjacency[N]]
+	edges int
+	cmp   func(a, b N) int
+}
+
+// New creates an empty graph whose nodes are ordered by their natural order.
+func New[N cmp.Ordered]() Graph[N] {
+	return NewFunc(cmp.Compare[N])
+}
+
+// NewFunc creates an empty graph whose nodes are ordered by the cmp function. Nodes for
+// which cmp returns zero are considered to be the same node.
+func NewFunc[N any](cmp func(a, b N) int) Graph[N] {
+	return Graph[N]{nodes: ordmap.New[N, adjacency[N]](cmp), cmp: cmp}
+}
+
+// Len returns the number of nodes in the graph.
+func (g Graph[N]) Len() int {
+	return g.nodes.Len()
+}
+
+// EdgeCount returns the number of edges in the graph.
+func (g Graph[N]) EdgeCount() int {
+	return g.edges
+}
+
+// HasNode returns whether n is a node of the graph.
+func (g Graph[N]) HasNode(n N) bool {
+	_, ok := g.nodes.Get(n)
+	return ok
+}
+
+// HasEdge returns whether the graph contains an edge from the node from to the node to.
+func (g Graph[N]) HasEdge(from, to N) bool {
+	adj, ok := g.nodes.Get(from)
+	if !ok {
+		return false
+	}
+	_, ok = adj.out.Get(to)
+	return ok
+}
+
+// AddNode returns a new graph containing the node n. If n is already a node of the
+// graph, g itself is returned.
+func (g Graph[N]) AddNode(n N) Graph[N] {
+	if g.HasNode(n) {
+		return g
+	}
+
+	g.nodes = g.nodes.Set(n, g.newAdjacency())
+	return g
+}
+
+// RemoveNode returns a new graph without the node n and without any of the edges
+// leading to or from it. If n is not a node of the graph, g itself is returned.
+func (g Graph[N]) RemoveNode(n N) Graph[N] {
+	adj, ok := g.nodes.Get(n)
+	if !ok {
+		return g
+	}
+
+	removed := adj.out.Len()
+	adj.out.Range(func(to N, _ struct{}) bool {
+		g.nodes = g.updateAdjacency(to, func(a adjacency[N]) adjacency[N] {
+			a.in = a.in.Delete(n)
+			return a
+		})
+		return true
+	})
+	adj.in.Range(func(from N, _ struct{}) bool {
+		if g.cmp(from, n) == 0 {
+			// self loops were already counted with the outgoing edges
+			return true
+		}
+		removed += 1
+		g.nodes = g.updateAdjacency(from, func(a adjacency[N]) adjacency[N] {
+			a.out = a.out.Delete(n)
+			return a
+		})
+		return true
+	})
+
+	g.nodes = g.nodes.Delete(n)
+	g.edges -= removed
+	return g
+}
+
+// AddEdge returns a new graph containing an edge from the node from to the node to. Both
+// nodes are added to the graph if they are not already part of it. If the edge already
+// exists, g itself is returned.
+func (g Graph[N]) AddEdge(from, to N) Graph[N] {
+	if g.HasEdge(from, to) {
+		return g
+	}
+
+	g = g.AddNode(from).AddNode(to)
+	g.nodes = g.updateAdjacency(from, func(a adjacency[N]) adjacency[N] {
+		a.out = a.out.Set(to, struct{}{})
+		return a
+	})
+	g.nodes = g.updateAdjacency(to, func(a adjacency[N]) adjacency[N] {
+		a.in = a.in.Set(from, struct{}{})
+		return a
+	})
+	g.edges += 1
+	return g
+}
+
+// RemoveEdge returns a new graph without the edge from the node from to the node to.
+// The nodes themselves remain part of the graph. If the edge does not exist, g itself is
+// returned.
+func (g Graph[N]) RemoveEdge(from, to N) Graph[N] {
+	if !g.HasEdge(from, to) {
+		return g
+	}
+
+	g.nodes = g.updateAdjacency(from, func(a adjacency[N]) adjacency[N] {
+		a.out = a.out.Delete(to)
+		return a
+	})
+	g.nodes = g.updateAdjacency(to, func(a adjacency[N]) adjacency[N] {
+		a.in = a.in.Delete(from)
+		return a
+	})
+	g.edges -= 1
+	return g
+}
+
+// Nodes returns a newly allocated slice holding all nodes of the graph in order. An
+// empty graph results in a nil slice.
+func (g Graph[N]) Nodes() []N {
+	var out []N
+	g.nodes.Range(func(n N, _ adjacency[N]) bool {
+		out = append(out, n)
+		return true
+	})
+	return out
+}
+
+// Successors returns a newly allocated slice holding the nodes that n has an edge to,
+// in order. If n has no outgoing edges or is not part of the graph, nil is returned.
+func (g Graph[N]) Successors(n N) []N {
+	adj, ok := g.nodes.Get(n)
+	if !ok {
+		return nil
+	}
+	return keys(adj.out)
+}
+
+// Predecessors returns a newly allocated slice holding the nodes that have an edge to n,
+// in order. If n has no incoming edges or is not part of the graph, nil is returned.
+func (g Graph[N]) Predecessors(n N) []N {
+	adj, ok := g.nodes.Get(n)
+	if !ok {
+		return nil
+	}
+	return keys(adj.in)
+}
+
+// TopologicalSort returns the nodes of the graph ordered such that every node appears
+// before all of its successors. Whenever multiple nodes could come next, the smallest
+// one is chosen which makes the result deterministic. If the graph contains a cycle,
+// ErrCycle is returned.
+func (g Graph[N]) TopologicalSort() ([]N, error) {
+	remaining := ordmap.New[N, int](g.cmp)
+	ready := ordmap.New[N, struct{}](g.cmp)
+	g.nodes.Range(func(n N, adj adjacency[N]) bool {
+		if adj.in.Len() == 0 {
+			ready = ready.Set(n, struct{}{})
+		} else {
+			remaining = remaining.Set(n, adj.in.Len())
+		}
+		return true
+	})
+
+	var out []N
+	for ready.Len() > 0 {
+		var next N
+		ready.Range(func(n N, _ struct{}) bool {
+			next = n
+			return false
+		})
+		ready = ready.Delete(next)
+		out = append(out, next)
+
+		adj, _ := g.nodes.Get(next)
+		adj.out.Range(func(succ N, _ struct{}) bool {
+			count, _ := remaining.Get(succ)
+			if count == 1 {
+				remaining = remaining.Delete(succ)
+				ready = ready.Set(succ, struct{}{})
+			} else {
+				remaining = remaining.Set(succ, count-1)
+			}
+			return true
+		})
+	}
+
+	if remaining.Len() > 0 {
+		return nil, ErrCycle
+	}
+	return out, nil
+}
+
+func (g Graph[N]) newAdjacency() adjacency[N] {
+	return adjacency[N]{
+		out: ordmap.New[N, struct{}](g.cmp),
+		in:  ordmap.New[N, struct{}](g.cmp),
+	}
+}
+
+// updateAdjacency returns the node map with the adjacency of the existing node n
+// replaced by the result of fn.
+func (g Graph[N]) updateAdjacency(n N, fn func(adjacency[N]) adjacency[N]) ordmap.Map[N, adjacency[N]] {
+	adj, _ := g.nodes.Get(n)
+	return g.nodes.Set(n, fn(adj))
+}
+
+func keys[N any](m ordmap.Map[N, struct{}]) []N {
+	var out []N
+	m.Range(func(n N, _ struct{}) bool {
+		out = append(out, n)
+		return true
+	})
+	return out
+}
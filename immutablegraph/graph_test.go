@@ -0,0 +1,174 @@
+package immutablegraph
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// dependencies builds a small dependency graph where an edge from a to b means that a
+// must be resolved before b.
+func dependencies() Graph[string] {
+	return New[string]().
+		AddEdge("config", "db").
+		AddEdge("config", "cache").
+		AddEdge("db", "api").
+		AddEdge("cache", "api").
+		AddEdge("log", "api").
+		AddNode("standalone")
+}
+
+func TestAddNodesAndEdges(t *testing.T) {
+	g := dependencies()
+
+	require.Equal(t, 6, g.Len())
+	require.Equal(t, 5, g.EdgeCount())
+	require.Equal(t, []string{"api", "cache", "config", "db", "log", "standalone"}, g.Nodes())
+
+	require.True(t, g.HasNode("standalone"))
+	require.False(t, g.HasNode("missing"))
+	require.True(t, g.HasEdge("config", "db"))
+	require.False(t, g.HasEdge("db", "config"))
+
+	require.Equal(t, []string{"cache", "db"}, g.Successors("config"))
+	require.Equal(t, []string{"cache", "db", "log"}, g.Predecessors("api"))
+	require.Nil(t, g.Successors("api"))
+	require.Nil(t, g.Predecessors("missing"))
+
+	// adding existing nodes and edges leaves the graph as is
+	same := g.AddNode("db").AddEdge("db", "api")
+	require.Equal(t, g.Nodes(), same.Nodes())
+	require.Equal(t, g.EdgeCount(), same.EdgeCount())
+}
+
+func TestRemove(t *testing.T) {
+	g := dependencies()
+
+	type testCase struct {
+		edit          func(Graph[string]) Graph[string]
+		nodes         []string
+		edges         int
+		successors    map[string][]string
+		predecessorsA []string
+	}
+
+	cases := map[string]testCase{
+		"remove edge": {
+			edit:          func(g Graph[string]) Graph[string] { return g.RemoveEdge("db", "api") },
+			nodes:         []string{"api", "cache", "config", "db", "log", "standalone"},
+			edges:         4,
+			successors:    map[string][]string{"db": nil, "config": {"cache", "db"}},
+			predecessorsA: []string{"cache", "log"},
+		},
+		"remove missing edge": {
+			edit:          func(g Graph[string]) Graph[string] { return g.RemoveEdge("api", "db") },
+			nodes:         []string{"api", "cache", "config", "db", "log", "standalone"},
+			edges:         5,
+			successors:    map[string][]string{"db": {"api"}},
+			predecessorsA: []string{"cache", "db", "log"},
+		},
+		"remove node": {
+			edit:          func(g Graph[string]) Graph[string] { return g.RemoveNode("db") },
+			nodes:         []string{"api", "cache", "config", "log", "standalone"},
+			edges:         3,
+			successors:    map[string][]string{"config": {"cache"}},
+			predecessorsA: []string{"cache", "log"},
+		},
+		"remove node with self loop": {
+			edit: func(g Graph[string]) Graph[string] {
+				return g.AddEdge("db", "db").RemoveNode("db")
+			},
+			nodes:         []string{"api", "cache", "config", "log", "standalone"},
+			edges:         3,
+			successors:    map[string][]string{"config": {"cache"}},
+			predecessorsA: []string{"cache", "log"},
+		},
+		"remove missing node": {
+			edit:          func(g Graph[string]) Graph[string] { return g.RemoveNode("missing") },
+			nodes:         []string{"api", "cache", "config", "db", "log", "standalone"},
+			edges:         5,
+			successors:    map[string][]string{"config": {"cache", "db"}},
+			predecessorsA: []string{"cache", "db", "log"},
+		},
+	}
+
+	for name, tcase := range cases {
+		tcase := tcase
+
+		t.Run(name, func(t *testing.T) {
+			actual := tcase.edit(g)
+			require.Equal(t, tcase.nodes, actual.Nodes())
+			require.Equal(t, tcase.edges, actual.EdgeCount())
+			for n, succ := range tcase.successors {
+				require.Equal(t, succ, actual.Successors(n), "successors of %s", n)
+			}
+			require.Equal(t, tcase.predecessorsA, actual.Predecessors("api"))
+
+			// the original graph is unaffected
+			require.Equal(t, dependencies().Nodes(), g.Nodes())
+			require.Equal(t, 5, g.EdgeCount())
+			require.Equal(t, []string{"cache", "db", "log"}, g.Predecessors("api"))
+		})
+	}
+}
+
+func TestTopologicalSort(t *testing.T) {
+	type testCase struct {
+		graph    Graph[string]
+		expected []string
+		err      error
+	}
+
+	cases := map[string]testCase{
+		"empty graph": {
+			graph:    New[string](),
+			expected: nil,
+		},
+		"dependencies": {
+			graph:    dependencies(),
+			expected: []string{"config", "cache", "db", "log", "api", "standalone"},
+		},
+		"cycle": {
+			graph: dependencies().AddEdge("api", "config"),
+			err:   ErrCycle,
+		},
+		"self loop": {
+			graph: New[string]().AddEdge("a", "a"),
+			err:   ErrCycle,
+		},
+	}
+
+	for name, tcase := range cases {
+		tcase := tcase
+
+		t.Run(name, func(t *testing.T) {
+			actual, err := tcase.graph.TopologicalSort()
+			if tcase.err != nil {
+				require.ErrorIs(t, err, tcase.err)
+				require.Nil(t, actual)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tcase.expected, actual)
+		})
+	}
+}
+
+func TestNewFunc(t *testing.T) {
+	type task struct {
+		id   int
+		name string
+	}
+
+	g := NewFunc(func(a, b task) int { return a.id - b.id }).
+		AddEdge(task{2, "build"}, task{3, "test"}).
+		AddEdge(task{1, "fetch"}, task{2, "build"})
+
+	order, err := g.TopologicalSort()
+	require.NoError(t, err)
+	require.Equal(t, []task{{1, "fetch"}, {2, "build"}, {3, "test"}}, order)
+
+	// nodes comparing equal are the same node
+	require.True(t, g.HasEdge(task{id: 1}, task{id: 2}))
+}
@@ -0,0 +1,174 @@
+// Package immutableheap provides a persistent priority queue. Inserting, deleting and
+// merging return new heaps which share structure with the heaps they were derived from.
+package immutableheap
+
+import "cmp"
+
+// node is a single node of a leftist heap. The rank of a node is the length of the
+// shortest path from it to an empty subtree. Leftist heaps maintain the invariant that
+// the rank of a left child is never smaller than the rank of its right sibling.
+type node[E any] struct {
+	value E
+	rank  int
+	left  *node[E]
+	right *node[E]
+}
+
+func (n *node[E]) getRank() int {
+	if n == nil {
+		return 0
+	}
+	return n.rank
+}
+
+// Heap is a persistent min-heap ordered by a comparison function, implemented as a
+// leftist heap. Insert, DeleteMin and Merge run in O(log n) worst case time and FindMin
+// runs in O(1). Because nodes are never modified after being created, every operation
+// copies only a single path through the heap and shares the remaining nodes.
+//
+// Heaps must be created with New or NewFunc. A Heap is safe for concurrent use by
+// multiple goroutines as none of its methods modify it.
+type Heap[E any] struct {
+	root *node[E]
+	size int
+	cmp  func(a, b E) int
+}
+
+// New creates a heap holding the specified elements ordered by their natural order.
+// The heap is built in O(n) time.
+func New[E cmp.Ordered](elems ...E) Heap[E] {
+	return NewFunc(cmp.Compare[E], elems...)
+}
+
+// NewFunc creates a heap holding the specified elements ordered by the cmp function.
+// Just like for slices.SortFunc, cmp(a, b) should return a negative number when a < b,
+// a positive number when a > b and zero when a == b. The heap is built in O(n) time.
+func NewFunc[E any](cmp func(a, b E) int, elems ...E) Heap[E] {
+	h := Heap[E]{cmp: cmp, size: len(elems)}
+	if len(elems) == 0 {
+		return h
+	}
+
+	// Repeatedly merge pairs of singleton heaps which results in O(n) total work.
+	level := make([]*node[E], len(elems))
+	for i, v := range elems {
+		level[i] = &node[E]{value: v, rank: 1}
+	}
+
+	for len(level) > 1 {
+		next := make([]*node[E], 0, (len(level)+1)/2)
+		for i := 0; i+1 < len(level); i += 2 {
+			next = append(next, h.merge(level[i], level[i+1]))
+		}
+		if len(level)%2 == 1 {
+			next = append(next, level[len(level)-1])
+		}
+		level = next
+	}
+
+	h.root = level[0]
+	return h
+}
+
+// Len returns the number of elements in the heap.
+func (h Heap[E]) Len() int {
+	return h.size
+}
+
+// Insert returns a new heap holding the elements of h along with v.
+func (h Heap[E]) Insert(v E) Heap[E] {
+	h.root = h.merge(h.root, &node[E]{value: v, rank: 1})
+	h.size += 1
+	return h
+}
+
+// FindMin returns the smallest element of the heap. The boolean return value will be
+// false if the heap is empty.
+func (h Heap[E]) FindMin() (E, bool) {
+	if h.root == nil {
+		var zero E
+		return zero, false
+	}
+	return h.root.value, true
+}
+
+// DeleteMin returns the smallest element of the heap along with a new heap holding the
+// remaining elements. The boolean return value will be false if the heap is empty in
+// which case the returned heap is also empty.
+func (h Heap[E]) DeleteMin() (E, Heap[E], bool) {
+	if h.root == nil {
+		var zero E
+		return zero, h, false
+	}
+
+	v := h.root.value
+	h.root = h.merge(h.root.left, h.root.right)
+	h.size -= 1
+	return v, h, true
+}
+
+// Merge returns a new heap holding the elements of both h and other. The resulting heap
+// uses the comparison function of h, so both heaps are expected to be ordered the same way.
+func (h Heap[E]) Merge(other Heap[E]) Heap[E] {
+	h.root = h.merge(h.root, other.root)
+	h.size += other.size
+	if h.cmp == nil {
+		h.cmp = other.cmp
+	}
+	return h
+}
+
+// Drain returns a newly allocated slice holding all elements of the heap in ascending
+// order. The heap itself is not modified. An empty heap results in a nil slice.
+func (h Heap[E]) Drain() []E {
+	if h.size == 0 {
+		return nil
+	}
+
+	s := make([]E, 0, h.size)
+	h.Range(func(v E) bool {
+		s = append(s, v)
+		return true
+	})
+	return s
+}
+
+// Range calls fn for each element of the heap in ascending order. Iteration stops early
+// if fn returns false. Each step costs O(log n) time as it is equivalent to a DeleteMin.
+func (h Heap[E]) Range(fn func(E) bool) {
+	for cur := h; cur.root != nil; {
+		var v E
+		v, cur, _ = cur.DeleteMin()
+		if !fn(v) {
+			return
+		}
+	}
+}
+
+// merge combines two leftist heaps by merging along their right spines. Only the nodes
+// on the merge path are copied.
+func (h Heap[E]) merge(a, b *node[E]) *node[E] {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+
+	if h.cmp(b.value, a.value) < 0 {
+		a, b = b, a
+	}
+
+	left := a.left
+	right := h.merge(a.right, b)
+	if left.getRank() < right.getRank() {
+		left, right = right, left
+	}
+
+	return &node[E]{
+		value: a.value,
+		rank:  right.getRank() + 1,
+		left:  left,
+		right: right,
+	}
+}
@@ -0,0 +1,143 @@
+package immutableheap
+
+import (
+	"math/rand"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	type testCase struct {
+		elems    []int
+		expected []int
+	}
+
+	cases := map[string]testCase{
+		"no elements": {
+			elems:    nil,
+			expected: nil,
+		},
+		"single element": {
+			elems:    []int{1},
+			expected: []int{1},
+		},
+		"unordered elements": {
+			elems:    []int{5, 3, 8, 1, 9, 2, 7},
+			expected: []int{1, 2, 3, 5, 7, 8, 9},
+		},
+		"duplicate elements": {
+			elems:    []int{3, 1, 3, 2, 1},
+			expected: []int{1, 1, 2, 3, 3},
+		},
+	}
+
+	for name, tcase := range cases {
+		tcase := tcase
+
+		t.Run(name, func(t *testing.T) {
+			t.Run("New", func(t *testing.T) {
+				h := New(tcase.elems...)
+				require.Equal(t, len(tcase.expected), h.Len())
+				require.Equal(t, tcase.expected, h.Drain())
+			})
+
+			t.Run("NewFunc", func(t *testing.T) {
+				// reverse ordering produces a max heap
+				h := NewFunc(func(a, b int) int { return b - a }, tcase.elems...)
+				require.Equal(t, len(tcase.expected), h.Len())
+
+				expected := slices.Clone(tcase.expected)
+				slices.Reverse(expected)
+				require.Equal(t, expected, h.Drain())
+			})
+		})
+	}
+}
+
+func TestEmpty(t *testing.T) {
+	h := New[int]()
+
+	_, ok := h.FindMin()
+	require.False(t, ok)
+
+	_, deleted, ok := h.DeleteMin()
+	require.False(t, ok)
+	require.Equal(t, 0, deleted.Len())
+}
+
+func TestInsertAndDeleteMin(t *testing.T) {
+	h := NewFunc(strings.Compare)
+	h1 := h.Insert("b")
+	h2 := h1.Insert("a")
+	h3 := h2.Insert("c")
+
+	smallest, ok := h3.FindMin()
+	require.True(t, ok)
+	require.Equal(t, "a", smallest)
+
+	v, h4, ok := h3.DeleteMin()
+	require.True(t, ok)
+	require.Equal(t, "a", v)
+
+	// every version remains intact
+	require.Equal(t, []string{"b"}, h1.Drain())
+	require.Equal(t, []string{"a", "b"}, h2.Drain())
+	require.Equal(t, []string{"a", "b", "c"}, h3.Drain())
+	require.Equal(t, []string{"b", "c"}, h4.Drain())
+}
+
+func TestMerge(t *testing.T) {
+	a := New(5, 1, 9)
+	b := New(4, 8, 2)
+
+	merged := a.Merge(b)
+	require.Equal(t, 6, merged.Len())
+	require.Equal(t, []int{1, 2, 4, 5, 8, 9}, merged.Drain())
+
+	// the merged heaps are not affected
+	require.Equal(t, []int{1, 5, 9}, a.Drain())
+	require.Equal(t, []int{2, 4, 8}, b.Drain())
+
+	// merging with an empty heap
+	require.Equal(t, []int{1, 5, 9}, a.Merge(New[int]()).Drain())
+	require.Equal(t, []int{1, 5, 9}, New[int]().Merge(a).Drain())
+}
+
+func TestRandomized(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	h := New[int]()
+	var model []int
+	for i := 0; i < 1000; i++ {
+		if len(model) > 0 && r.Intn(3) == 0 {
+			var v int
+			var ok bool
+			v, h, ok = h.DeleteMin()
+			require.True(t, ok)
+			require.Equal(t, model[0], v)
+			model = model[1:]
+		} else {
+			v := r.Intn(100)
+			h = h.Insert(v)
+			model = append(model, v)
+			slices.Sort(model)
+		}
+		require.Equal(t, len(model), h.Len())
+	}
+
+	require.Equal(t, model, h.Drain())
+}
+
+func TestRange(t *testing.T) {
+	h := New(4, 2, 5, 1, 3)
+
+	var smallest []int
+	h.Range(func(v int) bool {
+		smallest = append(smallest, v)
+		return len(smallest) < 3
+	})
+	require.Equal(t, []int{1, 2, 3}, smallest)
+}
@@ -0,0 +1,313 @@
+// Package immutablehistory provides a version history of immutable slices with support
+// for undo, redo and branching, similar to the edit history of a text editor.
+package immutablehistory
+
+import (
+	"errors"
+	"slices"
+
+	"github.com/mkeeler/go-immutable/immutableslice"
+)
+
+const (
+	// defaultCheckpointInterval is the default number of diffs stored between two full
+	// copies of a slice.
+	defaultCheckpointInterval = 32
+)
+
+// ErrVersionNotFound is returned by Checkout when the requested version never existed or
+// was discarded by the retention policy.
+var ErrVersionNotFound = errors.New("immutablehistory: version not found")
+
+type options struct {
+	maxVersions        int
+	checkpointInterval int
+}
+
+// Option configures a History.
+type Option func(*options)
+
+// WithMaxVersions bounds the number of versions retained by a History. Once the limit is
+// exceeded, the oldest version other than the current one is discarded. A limit of zero
+// or less, which is the default, retains all versions.
+func WithMaxVersions(n int) Option {
+	return func(o *options) {
+		o.maxVersions = n
+	}
+}
+
+// WithCheckpointInterval sets how many versions are stored as diffs before a full copy
+// of the slice is stored again. Larger intervals use less memory but make checking out
+// a version slower as more diffs need to be applied. The default interval is 32. An
+// interval of one or less stores every version as a full copy.
+func WithCheckpointInterval(n int) Option {
+	return func(o *options) {
+		o.checkpointInterval = n
+	}
+}
+
+// splice describes an edit replacing the elements s[i:j] with values.
+type splice[E any] struct {
+	i, j   int
+	values []E
+}
+
+// version is a single entry of the history. Versions are either checkpoints holding a
+// full copy of the slice or hold the splice turning their parent into them.
+type version[S ~[]E, E any] struct {
+	label    string
+	parent   int
+	children []int
+
+	checkpoint bool
+	full       S
+	diff       splice[E]
+	// depth is the number of diffs that need to be applied on top of the closest
+	// checkpoint to construct this version.
+	depth int
+}
+
+// History records successive versions of a slice. Every edit creates a new version whose
+// parent is the current version and makes it current. Undo and Redo move between a
+// version and its parent while Checkout jumps to any retained version. Editing after an
+// undo starts a new branch, the versions of the previous branch remain available.
+//
+// Versions are numbered sequentially starting with zero for the initial slice. Rather
+// than keeping full copies, most versions only store the difference to their parent
+// with a full copy being kept at regular intervals.
+//
+// Histories must be created with New or NewFunc. Unlike the other types of this module a
+// History is mutable, and it is not safe for concurrent use by multiple goroutines. The
+// slices it returns are never modified though and may be shared freely.
+type History[S ~[]E, E any] struct {
+	versions map[int]*version[S, E]
+	current  int
+	next     int
+	value    S
+	eq       func(a, b E) bool
+	opts     options
+}
+
+// New creates a history whose initial version, numbered zero, is the specified slice.
+func New[S ~[]E, E comparable](initial S, opts ...Option) *History[S, E] {
+	return NewFunc(initial, func(a, b E) bool { return a == b }, opts...)
+}
+
+// NewFunc creates a history whose initial version, numbered zero, is the specified
+// slice. The eq function is used by Commit to determine which elements changed.
+func NewFunc[S ~[]E, E any](initial S, eq func(a, b E) bool, opts ...Option) *History[S, E] {
+	o := options{checkpointInterval: defaultCheckpointInterval}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	h := &History[S, E]{
+		versions: make(map[int]*version[S, E]),
+		eq:       eq,
+		opts:     o,
+	}
+	h.value = immutableslice.Concat(initial)
+	h.versions[0] = &version[S, E]{parent: -1, checkpoint: true, full: h.value}
+	h.next = 1
+	return h
+}
+
+// Current returns the slice of the current version. The slice must not be modified.
+func (h *History[S, E]) Current() S {
+	return h.value
+}
+
+// Version returns the number of the current version.
+func (h *History[S, E]) Version() int {
+	return h.current
+}
+
+// Label returns the label recorded for the version v. The boolean return value will be
+// false if the version is not retained by the history.
+func (h *History[S, E]) Label(v int) (string, bool) {
+	ver, ok := h.versions[v]
+	if !ok {
+		return "", false
+	}
+	return ver.label, true
+}
+
+// Versions returns the numbers of all retained versions in ascending order.
+func (h *History[S, E]) Versions() []int {
+	out := make([]int, 0, len(h.versions))
+	for v := range h.versions {
+		out = append(out, v)
+	}
+	slices.Sort(out)
+	return out
+}
+
+// Insert records a new version where the values v are inserted at index i of the
+// current slice and returns its number. It panics if i is out of range.
+func (h *History[S, E]) Insert(label string, i int, v ...E) int {
+	return h.Replace(label, i, i, v...)
+}
+
+// Delete records a new version where the elements s[i:j] are removed from the current
+// slice s and returns its number. It panics if s[i:j] is not a valid slice of s.
+func (h *History[S, E]) Delete(label string, i, j int) int {
+	return h.Replace(label, i, j)
+}
+
+// Replace records a new version where the elements s[i:j] of the current slice s are
+// replaced by the values v and returns its number. It panics if s[i:j] is not a valid
+// slice of s.
+func (h *History[S, E]) Replace(label string, i, j int, v ...E) int {
+	value := immutableslice.Replace(h.value, i, j, v...)
+	return h.record(label, value, splice[E]{i: i, j: j, values: slices.Clone(v)})
+}
+
+// Commit records s as a new version and returns its number. Only the range of elements
+// that differs from the current slice is stored. Elements of s which the equality
+// function considers unchanged keep their value from the current slice, so with NewFunc
+// the new current slice may differ from s.
+func (h *History[S, E]) Commit(label string, s S) int {
+	old := h.value
+
+	prefix := 0
+	for prefix < len(old) && prefix < len(s) && h.eq(old[prefix], s[prefix]) {
+		prefix += 1
+	}
+	suffix := 0
+	for suffix < len(old)-prefix && suffix < len(s)-prefix &&
+		h.eq(old[len(old)-1-suffix], s[len(s)-1-suffix]) {
+		suffix += 1
+	}
+
+	diff := splice[E]{
+		i:      prefix,
+		j:      len(old) - suffix,
+		values: slices.Clone(s[prefix : len(s)-suffix]),
+	}
+	return h.record(label, immutableslice.Replace(old, diff.i, diff.j, diff.values...), diff)
+}
+
+// Undo makes the parent of the current version current. It returns false if the current
+// version has no retained parent.
+func (h *History[S, E]) Undo() bool {
+	parent := h.versions[h.current].parent
+	if parent < 0 {
+		return false
+	}
+	h.moveTo(parent)
+	return true
+}
+
+// Redo makes the most recently created child of the current version current. It returns
+// false if the current version has no children.
+func (h *History[S, E]) Redo() bool {
+	children := h.versions[h.current].children
+	if len(children) == 0 {
+		return false
+	}
+	h.moveTo(children[len(children)-1])
+	return true
+}
+
+// Checkout makes the version v current. ErrVersionNotFound is returned if the version is
+// not retained by the history.
+func (h *History[S, E]) Checkout(v int) error {
+	if _, ok := h.versions[v]; !ok {
+		return ErrVersionNotFound
+	}
+	h.moveTo(v)
+	return nil
+}
+
+// record adds value as a new child of the current version, makes it current and applies
+// the retention policy.
+func (h *History[S, E]) record(label string, value S, diff splice[E]) int {
+	parent := h.versions[h.current]
+	ver := &version[S, E]{
+		label:  label,
+		parent: h.current,
+		diff:   diff,
+		depth:  parent.depth + 1,
+	}
+	if ver.depth >= h.opts.checkpointInterval {
+		h.makeCheckpoint(ver, value)
+	}
+
+	id := h.next
+	h.next += 1
+	h.versions[id] = ver
+	parent.children = append(parent.children, id)
+	h.current = id
+	h.value = value
+
+	h.evict()
+	return id
+}
+
+// evict discards the oldest versions until the history holds no more than the maximum
+// number of versions. Eviction happens right after recording a version, so the current
+// version is the newest one and is never discarded.
+func (h *History[S, E]) evict() {
+	if h.opts.maxVersions <= 0 {
+		return
+	}
+
+	for len(h.versions) > h.opts.maxVersions && len(h.versions) > 1 {
+		oldest := h.current
+		for v := range h.versions {
+			if v < oldest {
+				oldest = v
+			}
+		}
+		h.remove(oldest)
+	}
+}
+
+// remove discards the version v which must be the oldest retained version. As parents
+// are always older than their children, v has no retained parent. Its children are
+// turned into checkpoints as they can no longer be constructed from v.
+func (h *History[S, E]) remove(v int) {
+	for _, c := range h.versions[v].children {
+		child := h.versions[c]
+		if !child.checkpoint {
+			h.makeCheckpoint(child, h.materialize(c))
+		}
+		child.parent = -1
+	}
+	delete(h.versions, v)
+}
+
+// makeCheckpoint stores a full copy of value in ver.
+func (h *History[S, E]) makeCheckpoint(ver *version[S, E], value S) {
+	ver.checkpoint = true
+	ver.full = value
+	ver.diff = splice[E]{}
+	ver.depth = 0
+}
+
+func (h *History[S, E]) moveTo(v int) {
+	h.value = h.materialize(v)
+	h.current = v
+}
+
+// materialize constructs the slice of version v by applying the diffs between the
+// closest checkpoint and v.
+func (h *History[S, E]) materialize(v int) S {
+	if v == h.current {
+		return h.value
+	}
+
+	var chain []*version[S, E]
+	ver := h.versions[v]
+	for !ver.checkpoint {
+		chain = append(chain, ver)
+		ver = h.versions[ver.parent]
+	}
+
+	value := ver.full
+	for i := len(chain) - 1; i >= 0; i-- {
+		d := chain[i].diff
+		value = immutableslice.Replace(value, d.i, d.j, d.values...)
+	}
+	return value
+}
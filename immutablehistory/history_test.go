@@ -0,0 +1,210 @@
+package immutablehistory
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEdits(t *testing.T) {
+	h := New([]string{"a", "b", "c"})
+	require.Equal(t, 0, h.Version())
+	require.Equal(t, []string{"a", "b", "c"}, h.Current())
+
+	type step struct {
+		edit     func() int
+		label    string
+		expected []string
+	}
+
+	steps := []step{
+		{
+			edit:     func() int { return h.Insert("insert", 1, "x", "y") },
+			label:    "insert",
+			expected: []string{"a", "x", "y", "b", "c"},
+		},
+		{
+			edit:     func() int { return h.Delete("delete", 0, 2) },
+			label:    "delete",
+			expected: []string{"y", "b", "c"},
+		},
+		{
+			edit:     func() int { return h.Replace("replace", 1, 3, "z") },
+			label:    "replace",
+			expected: []string{"y", "z"},
+		},
+		{
+			edit:     func() int { return h.Commit("commit", []string{"w", "y", "z", "v"}) },
+			label:    "commit",
+			expected: []string{"w", "y", "z", "v"},
+		},
+		{
+			edit:     func() int { return h.Commit("", nil) },
+			label:    "",
+			expected: nil,
+		},
+	}
+
+	for i, s := range steps {
+		v := s.edit()
+		require.Equal(t, i+1, v)
+		require.Equal(t, v, h.Version())
+		require.Equal(t, s.expected, h.Current())
+
+		label, ok := h.Label(v)
+		require.True(t, ok)
+		require.Equal(t, s.label, label)
+	}
+
+	// walk back through every version and forward again
+	for i := len(steps) - 2; i >= 0; i-- {
+		require.True(t, h.Undo())
+		require.Equal(t, steps[i].expected, h.Current())
+	}
+	require.True(t, h.Undo())
+	require.Equal(t, []string{"a", "b", "c"}, h.Current())
+	require.False(t, h.Undo())
+
+	for _, s := range steps {
+		require.True(t, h.Redo())
+		require.Equal(t, s.expected, h.Current())
+	}
+	require.False(t, h.Redo())
+
+	_, ok := h.Label(100)
+	require.False(t, ok)
+}
+
+func TestBranching(t *testing.T) {
+	h := New([]int{1})
+	h.Insert("", 1, 2) // 1
+	h.Insert("", 2, 3) // 2
+
+	require.True(t, h.Undo())
+	v := h.Insert("branch", 2, 4)
+	require.Equal(t, 3, v)
+	require.Equal(t, []int{1, 2, 4}, h.Current())
+
+	// redo follows the most recent branch
+	require.True(t, h.Undo())
+	require.True(t, h.Redo())
+	require.Equal(t, 3, h.Version())
+
+	// the abandoned branch is still available
+	require.NoError(t, h.Checkout(2))
+	require.Equal(t, []int{1, 2, 3}, h.Current())
+	require.Equal(t, []int{0, 1, 2, 3}, h.Versions())
+
+	require.ErrorIs(t, h.Checkout(4), ErrVersionNotFound)
+	require.Equal(t, 2, h.Version())
+}
+
+func TestCheckpoints(t *testing.T) {
+	type testCase struct {
+		interval int
+	}
+
+	cases := map[string]testCase{
+		"default":       {interval: defaultCheckpointInterval},
+		"every version": {interval: 1},
+		"every third":   {interval: 3},
+	}
+
+	for name, tcase := range cases {
+		tcase := tcase
+
+		t.Run(name, func(t *testing.T) {
+			h := New([]int(nil), WithCheckpointInterval(tcase.interval))
+
+			var expected [][]int
+			expected = append(expected, nil)
+			for i := 0; i < 50; i++ {
+				h.Insert("", i/2, i)
+				expected = append(expected, h.Current())
+			}
+
+			for v, exp := range expected {
+				require.NoError(t, h.Checkout(v))
+				require.Equal(t, exp, h.Current())
+			}
+
+			for v, ver := range h.versions {
+				require.Less(t, ver.depth, max(tcase.interval, 1), "version %d", v)
+			}
+		})
+	}
+}
+
+func TestMaxVersions(t *testing.T) {
+	h := New([]string{"a"}, WithMaxVersions(3), WithCheckpointInterval(10))
+
+	h.Insert("1", 1, "b")
+	h.Insert("2", 2, "c")
+	h.Insert("3", 3, "d")
+	h.Insert("4", 4, "e")
+	require.Equal(t, []int{2, 3, 4}, h.Versions())
+
+	_, ok := h.Label(1)
+	require.False(t, ok)
+	require.ErrorIs(t, h.Checkout(0), ErrVersionNotFound)
+
+	// the oldest retained version can still be constructed after its ancestors are gone
+	require.NoError(t, h.Checkout(2))
+	require.Equal(t, []string{"a", "b", "c"}, h.Current())
+	require.False(t, h.Undo())
+
+	// discarding version 2 leaves both of its children without a parent
+	h.Insert("5", 3, "x")
+	require.Equal(t, []int{3, 4, 5}, h.Versions())
+	require.Equal(t, []string{"a", "b", "c", "x"}, h.Current())
+	require.False(t, h.Undo())
+
+	require.NoError(t, h.Checkout(4))
+	require.Equal(t, []string{"a", "b", "c", "d", "e"}, h.Current())
+	require.True(t, h.Undo())
+	require.Equal(t, []string{"a", "b", "c", "d"}, h.Current())
+	require.False(t, h.Undo())
+}
+
+func TestMaxVersionsSingle(t *testing.T) {
+	h := New([]int{1}, WithMaxVersions(1))
+	h.Insert("", 1, 2)
+	h.Insert("", 2, 3)
+
+	require.Equal(t, []int{2}, h.Versions())
+	require.Equal(t, []int{1, 2, 3}, h.Current())
+	require.False(t, h.Undo())
+}
+
+func TestNewFunc(t *testing.T) {
+	h := NewFunc([]string{"A", "b", "C"}, strings.EqualFold)
+
+	// elements which only differ in case are considered unchanged
+	v := h.Commit("", []string{"a", "x", "c"})
+	diff := h.versions[v].diff
+	require.Equal(t, 1, diff.i)
+	require.Equal(t, 2, diff.j)
+	require.Equal(t, []string{"x"}, diff.values)
+
+	// the unchanged elements keep their value from the previous version
+	require.Equal(t, []string{"A", "x", "C"}, h.Current())
+	require.True(t, h.Undo())
+	require.True(t, h.Redo())
+	require.Equal(t, []string{"A", "x", "C"}, h.Current())
+}
+
+func TestInputsNotRetained(t *testing.T) {
+	initial := []int{1, 2, 3}
+	values := []int{4, 5}
+
+	h := New(initial)
+	h.Insert("", 0, values...)
+
+	initial[0] = 10
+	values[0] = 10
+
+	require.Equal(t, []int{4, 5, 1, 2, 3}, h.Current())
+	require.True(t, h.Undo())
+	require.Equal(t, []int{1, 2, 3}, h.Current())
+}
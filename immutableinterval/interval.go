@@ -0,0 +1,285 @@
+// Package immutableinterval provides a persistent interval tree. Updates return new trees
+// which share all unmodified nodes with the tree they were derived from so readers can
+// keep querying older snapshots while the tree is being updated.
+package immutableinterval
+
+import "cmp"
+
+// Interval is a closed interval holding every point p where Start <= p <= End.
+type Interval[K cmp.Ordered] struct {
+	Start K
+	End   K
+}
+
+// Contains returns whether the point p lies within the interval.
+func (iv Interval[K]) Contains(p K) bool {
+	return iv.Start <= p && p <= iv.End
+}
+
+// Encloses returns whether every point of other also lies within iv.
+func (iv Interval[K]) Encloses(other Interval[K]) bool {
+	return iv.Start <= other.Start && other.End <= iv.End
+}
+
+// Overlaps returns whether iv and other have at least one point in common.
+func (iv Interval[K]) Overlaps(other Interval[K]) bool {
+	return iv.Start <= other.End && other.Start <= iv.End
+}
+
+func (iv Interval[K]) compare(other Interval[K]) int {
+	if c := cmp.Compare(iv.Start, other.Start); c != 0 {
+		return c
+	}
+	return cmp.Compare(iv.End, other.End)
+}
+
+// Entry is an interval stored within a tree along with its associated value.
+type Entry[K cmp.Ordered, V any] struct {
+	Interval Interval[K]
+	Value    V
+}
+
+// node is a node of an AVL tree ordered by interval. Each node is augmented with the
+// largest End of all intervals within its subtree which allows queries to skip subtrees
+// that cannot contain any overlapping intervals.
+type node[K cmp.Ordered, V any] struct {
+	entry  Entry[K, V]
+	left   *node[K, V]
+	right  *node[K, V]
+	height int
+	maxEnd K
+}
+
+func (n *node[K, V]) getHeight() int {
+	if n == nil {
+		return 0
+	}
+	return n.height
+}
+
+func newNode[K cmp.Ordered, V any](entry Entry[K, V], left, right *node[K, V]) *node[K, V] {
+	n := &node[K, V]{
+		entry:  entry,
+		left:   left,
+		right:  right,
+		height: max(left.getHeight(), right.getHeight()) + 1,
+		maxEnd: entry.Interval.End,
+	}
+	if left != nil && left.maxEnd > n.maxEnd {
+		n.maxEnd = left.maxEnd
+	}
+	if right != nil && right.maxEnd > n.maxEnd {
+		n.maxEnd = right.maxEnd
+	}
+	return n
+}
+
+// Tree is a persistent interval tree mapping intervals to values. Each distinct interval
+// is stored at most once, so inserting an interval that is already present replaces its
+// value. Insert and Delete run in O(log n) time and only copy the nodes along the path
+// to the modified interval. Queries run in O(log n + m) time where m is the number of
+// matching intervals.
+//
+// The zero value of a Tree is an empty tree ready to use. A Tree is safe for concurrent
+// use by multiple goroutines as none of its methods modify it.
+type Tree[K cmp.Ordered, V any] struct {
+	root *node[K, V]
+	size int
+}
+
+// Len returns the number of intervals stored in the tree.
+func (t Tree[K, V]) Len() int {
+	return t.size
+}
+
+// Get returns the value stored for the interval along with whether it was found.
+func (t Tree[K, V]) Get(iv Interval[K]) (V, bool) {
+	n := t.root
+	for n != nil {
+		switch c := iv.compare(n.entry.Interval); {
+		case c < 0:
+			n = n.left
+		case c > 0:
+			n = n.right
+		default:
+			return n.entry.Value, true
+		}
+	}
+
+	var zero V
+	return zero, false
+}
+
+// Insert returns a new tree with the interval set to the value v. It panics if the
+// start of the interval is after its end.
+func (t Tree[K, V]) Insert(iv Interval[K], v V) Tree[K, V] {
+	if iv.Start > iv.End {
+		panic("immutableinterval: interval start is after its end")
+	}
+
+	root, added := insert(t.root, Entry[K, V]{Interval: iv, Value: v})
+	t.root = root
+	if added {
+		t.size += 1
+	}
+	return t
+}
+
+// Delete returns a new tree without the interval. If the interval is not present, the
+// returned tree is identical to t.
+func (t Tree[K, V]) Delete(iv Interval[K]) Tree[K, V] {
+	root, deleted := remove(t.root, iv)
+	if !deleted {
+		return t
+	}
+	return Tree[K, V]{root: root, size: t.size - 1}
+}
+
+// Overlapping returns all entries whose intervals overlap iv, ordered by interval.
+// If no entries overlap, the returned slice is nil.
+func (t Tree[K, V]) Overlapping(iv Interval[K]) []Entry[K, V] {
+	var out []Entry[K, V]
+	overlapping(t.root, iv, &out)
+	return out
+}
+
+// OverlappingPoint returns all entries whose intervals contain the point p, ordered by
+// interval. If no entries contain p, the returned slice is nil.
+func (t Tree[K, V]) OverlappingPoint(p K) []Entry[K, V] {
+	return t.Overlapping(Interval[K]{Start: p, End: p})
+}
+
+// Enclosing returns all entries whose intervals fully enclose iv, ordered by interval.
+// If no entries enclose iv, the returned slice is nil.
+func (t Tree[K, V]) Enclosing(iv Interval[K]) []Entry[K, V] {
+	var out []Entry[K, V]
+	enclosing(t.root, iv, &out)
+	return out
+}
+
+// Range calls fn for each entry of the tree ordered by interval. Intervals are ordered
+// by their start and then by their end. Iteration stops early if fn returns false.
+func (t Tree[K, V]) Range(fn func(Entry[K, V]) bool) {
+	walk(t.root, fn)
+}
+
+func walk[K cmp.Ordered, V any](n *node[K, V], fn func(Entry[K, V]) bool) bool {
+	if n == nil {
+		return true
+	}
+	return walk(n.left, fn) && fn(n.entry) && walk(n.right, fn)
+}
+
+func overlapping[K cmp.Ordered, V any](n *node[K, V], iv Interval[K], out *[]Entry[K, V]) {
+	// no interval in this subtree ends at or after the start of iv
+	if n == nil || n.maxEnd < iv.Start {
+		return
+	}
+
+	overlapping(n.left, iv, out)
+	if n.entry.Interval.Overlaps(iv) {
+		*out = append(*out, n.entry)
+	}
+
+	// intervals in the right subtree start no earlier than this one
+	if n.entry.Interval.Start <= iv.End {
+		overlapping(n.right, iv, out)
+	}
+}
+
+func enclosing[K cmp.Ordered, V any](n *node[K, V], iv Interval[K], out *[]Entry[K, V]) {
+	// no interval in this subtree extends far enough to enclose iv
+	if n == nil || n.maxEnd < iv.End {
+		return
+	}
+
+	enclosing(n.left, iv, out)
+	if n.entry.Interval.Encloses(iv) {
+		*out = append(*out, n.entry)
+	}
+
+	// intervals in the right subtree start no earlier than this one
+	if n.entry.Interval.Start <= iv.Start {
+		enclosing(n.right, iv, out)
+	}
+}
+
+func insert[K cmp.Ordered, V any](n *node[K, V], entry Entry[K, V]) (*node[K, V], bool) {
+	if n == nil {
+		return newNode(entry, nil, nil), true
+	}
+
+	switch c := entry.Interval.compare(n.entry.Interval); {
+	case c < 0:
+		left, added := insert(n.left, entry)
+		return balance(n.entry, left, n.right), added
+	case c > 0:
+		right, added := insert(n.right, entry)
+		return balance(n.entry, n.left, right), added
+	default:
+		return newNode(entry, n.left, n.right), false
+	}
+}
+
+func remove[K cmp.Ordered, V any](n *node[K, V], iv Interval[K]) (*node[K, V], bool) {
+	if n == nil {
+		return nil, false
+	}
+
+	switch c := iv.compare(n.entry.Interval); {
+	case c < 0:
+		left, deleted := remove(n.left, iv)
+		if !deleted {
+			return n, false
+		}
+		return balance(n.entry, left, n.right), true
+	case c > 0:
+		right, deleted := remove(n.right, iv)
+		if !deleted {
+			return n, false
+		}
+		return balance(n.entry, n.left, right), true
+	}
+
+	if n.left == nil {
+		return n.right, true
+	}
+	if n.right == nil {
+		return n.left, true
+	}
+
+	// replace the node with its in-order successor
+	successor, right := removeMin(n.right)
+	return balance(successor, n.left, right), true
+}
+
+func removeMin[K cmp.Ordered, V any](n *node[K, V]) (Entry[K, V], *node[K, V]) {
+	if n.left == nil {
+		return n.entry, n.right
+	}
+
+	entry, left := removeMin(n.left)
+	return entry, balance(n.entry, left, n.right)
+}
+
+// balance creates a node from the entry and two subtrees whose heights differ by at
+// most two, rotating as necessary to restore the AVL invariant.
+func balance[K cmp.Ordered, V any](entry Entry[K, V], left, right *node[K, V]) *node[K, V] {
+	hl, hr := left.getHeight(), right.getHeight()
+	switch {
+	case hl > hr+1:
+		if left.left.getHeight() >= left.right.getHeight() {
+			return newNode(left.entry, left.left, newNode(entry, left.right, right))
+		}
+		lr := left.right
+		return newNode(lr.entry, newNode(left.entry, left.left, lr.left), newNode(entry, lr.right, right))
+	case hr > hl+1:
+		if right.right.getHeight() >= right.left.getHeight() {
+			return newNode(right.entry, newNode(entry, left, right.left), right.right)
+		}
+		rl := right.left
+		return newNode(rl.entry, newNode(entry, left, rl.left), newNode(right.entry, rl.right, right.right))
+	default:
+		return newNode(entry, left, right)
+	}
+}
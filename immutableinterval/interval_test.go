@@ -0,0 +1,210 @@
+package immutableinterval
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func iv(start, end int) Interval[int] {
+	return Interval[int]{Start: start, End: end}
+}
+
+// intervals returns the intervals of the entries in order.
+func intervals(entries []Entry[int, string]) []Interval[int] {
+	var out []Interval[int]
+	for _, e := range entries {
+		out = append(out, e.Interval)
+	}
+	return out
+}
+
+// requireValid verifies the AVL and augmentation invariants of every node.
+func requireValid(t *testing.T, n *node[int, string]) {
+	t.Helper()
+
+	if n == nil {
+		return
+	}
+
+	require.LessOrEqual(t, n.left.getHeight()-n.right.getHeight(), 1)
+	require.LessOrEqual(t, n.right.getHeight()-n.left.getHeight(), 1)
+
+	maxEnd := n.entry.Interval.End
+	if n.left != nil {
+		maxEnd = max(maxEnd, n.left.maxEnd)
+		require.Negative(t, n.left.entry.Interval.compare(n.entry.Interval))
+	}
+	if n.right != nil {
+		maxEnd = max(maxEnd, n.right.maxEnd)
+		require.Positive(t, n.right.entry.Interval.compare(n.entry.Interval))
+	}
+	require.Equal(t, maxEnd, n.maxEnd)
+
+	requireValid(t, n.left)
+	requireValid(t, n.right)
+}
+
+func schedule() Tree[int, string] {
+	return Tree[int, string]{}.
+		Insert(iv(9, 17), "work").
+		Insert(iv(12, 13), "lunch").
+		Insert(iv(8, 9), "commute").
+		Insert(iv(17, 18), "commute").
+		Insert(iv(0, 24), "day").
+		Insert(iv(14, 16), "meeting")
+}
+
+func TestInsertAndGet(t *testing.T) {
+	tree := schedule()
+	require.Equal(t, 6, tree.Len())
+	requireValid(t, tree.root)
+
+	v, ok := tree.Get(iv(12, 13))
+	require.True(t, ok)
+	require.Equal(t, "lunch", v)
+
+	_, ok = tree.Get(iv(12, 14))
+	require.False(t, ok)
+
+	// inserting an existing interval replaces its value
+	updated := tree.Insert(iv(12, 13), "brunch")
+	require.Equal(t, 6, updated.Len())
+	v, _ = updated.Get(iv(12, 13))
+	require.Equal(t, "brunch", v)
+	v, _ = tree.Get(iv(12, 13))
+	require.Equal(t, "lunch", v)
+
+	require.Panics(t, func() {
+		tree.Insert(iv(5, 4), "invalid")
+	})
+}
+
+func TestDelete(t *testing.T) {
+	tree := schedule()
+
+	deleted := tree.Delete(iv(9, 17))
+	require.Equal(t, 5, deleted.Len())
+	requireValid(t, deleted.root)
+	_, ok := deleted.Get(iv(9, 17))
+	require.False(t, ok)
+
+	// the original is unaffected
+	_, ok = tree.Get(iv(9, 17))
+	require.True(t, ok)
+	require.Equal(t, 6, tree.Len())
+
+	// deleting a missing interval returns an identical tree
+	same := tree.Delete(iv(1, 2))
+	require.Equal(t, tree, same)
+}
+
+func TestQueries(t *testing.T) {
+	tree := schedule()
+
+	type testCase struct {
+		query    func() []Entry[int, string]
+		expected []Interval[int]
+	}
+
+	cases := map[string]testCase{
+		"overlapping point": {
+			query:    func() []Entry[int, string] { return tree.OverlappingPoint(9) },
+			expected: []Interval[int]{iv(0, 24), iv(8, 9), iv(9, 17)},
+		},
+		"overlapping point with no matches": {
+			query:    func() []Entry[int, string] { return tree.OverlappingPoint(30) },
+			expected: nil,
+		},
+		"overlapping range": {
+			query:    func() []Entry[int, string] { return tree.Overlapping(iv(13, 15)) },
+			expected: []Interval[int]{iv(0, 24), iv(9, 17), iv(12, 13), iv(14, 16)},
+		},
+		"overlapping range touching ends": {
+			query:    func() []Entry[int, string] { return tree.Overlapping(iv(18, 20)) },
+			expected: []Interval[int]{iv(0, 24), iv(17, 18)},
+		},
+		"enclosing": {
+			query:    func() []Entry[int, string] { return tree.Enclosing(iv(12, 13)) },
+			expected: []Interval[int]{iv(0, 24), iv(9, 17), iv(12, 13)},
+		},
+		"enclosing with no matches": {
+			query:    func() []Entry[int, string] { return tree.Enclosing(iv(-1, 3)) },
+			expected: nil,
+		},
+	}
+
+	for name, tcase := range cases {
+		tcase := tcase
+
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, tcase.expected, intervals(tcase.query()))
+		})
+	}
+}
+
+func TestRange(t *testing.T) {
+	tree := schedule()
+
+	var all []Interval[int]
+	tree.Range(func(e Entry[int, string]) bool {
+		all = append(all, e.Interval)
+		return true
+	})
+	require.Equal(t, []Interval[int]{iv(0, 24), iv(8, 9), iv(9, 17), iv(12, 13), iv(14, 16), iv(17, 18)}, all)
+
+	count := 0
+	tree.Range(func(Entry[int, string]) bool {
+		count += 1
+		return count < 2
+	})
+	require.Equal(t, 2, count)
+}
+
+func TestRandomized(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	var tree Tree[int, string]
+	model := map[Interval[int]]string{}
+
+	for i := 0; i < 1000; i++ {
+		start := r.Intn(100)
+		current := iv(start, start+r.Intn(20))
+
+		if r.Intn(3) == 0 {
+			tree = tree.Delete(current)
+			delete(model, current)
+		} else {
+			tree = tree.Insert(current, "x")
+			model[current] = "x"
+		}
+		require.Equal(t, len(model), tree.Len())
+	}
+	requireValid(t, tree.root)
+
+	for q := 0; q < 100; q++ {
+		start := r.Intn(120)
+		query := iv(start, start+r.Intn(10))
+
+		var expectedOverlap, expectedEnclose []Interval[int]
+		for k := range model {
+			if k.Overlaps(query) {
+				expectedOverlap = append(expectedOverlap, k)
+			}
+			if k.Encloses(query) {
+				expectedEnclose = append(expectedEnclose, k)
+			}
+		}
+
+		sortIntervals := func(s []Interval[int]) {
+			sort.Slice(s, func(i, j int) bool { return s[i].compare(s[j]) < 0 })
+		}
+		sortIntervals(expectedOverlap)
+		sortIntervals(expectedEnclose)
+
+		require.Equal(t, expectedOverlap, intervals(tree.Overlapping(query)))
+		require.Equal(t, expectedEnclose, intervals(tree.Enclosing(query)))
+	}
+}
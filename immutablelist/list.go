@@ -0,0 +1,156 @@
+// Package immutablelist provides a persistent singly linked list. Prepending an element
+// to a list creates a new list that shares the entirety of the original list as its tail.
+package immutablelist
+
+// node is a single cell of the list. Each node caches the length of the list starting
+// at that node so that computing the length of a list is a constant time operation.
+type node[E any] struct {
+	value E
+	next  *node[E]
+	len   int
+}
+
+// List is a persistent singly linked list, often called a cons list. Lists are never
+// modified after they are created so any number of lists may share a common tail.
+//
+// The zero value of a List is an empty list ready to use. A List is safe for concurrent
+// use by multiple goroutines as none of its methods modify it.
+type List[E any] struct {
+	head *node[E]
+}
+
+// New creates a list containing the specified elements in the order they were given.
+func New[E any](elems ...E) List[E] {
+	var l List[E]
+	for i := len(elems) - 1; i >= 0; i-- {
+		l = l.Cons(elems[i])
+	}
+	return l
+}
+
+// FromSlice creates a list containing the elements of s in the same order. It is
+// equivalent to New(s...) but reads better when converting an existing slice.
+func FromSlice[S ~[]E, E any](s S) List[E] {
+	return New(s...)
+}
+
+// Cons returns a new list with v as its head and l as its tail. This runs in constant
+// time and l is shared rather than copied.
+func (l List[E]) Cons(v E) List[E] {
+	return List[E]{head: &node[E]{value: v, next: l.head, len: l.Len() + 1}}
+}
+
+// IsEmpty returns whether the list contains no elements.
+func (l List[E]) IsEmpty() bool {
+	return l.head == nil
+}
+
+// Len returns the number of elements in the list.
+func (l List[E]) Len() int {
+	if l.head == nil {
+		return 0
+	}
+	return l.head.len
+}
+
+// Head returns the first element of the list. The boolean return value will be false
+// if the list is empty.
+func (l List[E]) Head() (E, bool) {
+	if l.head == nil {
+		var zero E
+		return zero, false
+	}
+	return l.head.value, true
+}
+
+// Tail returns the list of all elements after the head. The tail of an empty list is
+// an empty list.
+func (l List[E]) Tail() List[E] {
+	if l.head == nil {
+		return l
+	}
+	return List[E]{head: l.head.next}
+}
+
+// Reverse returns a new list holding the elements of l in reverse order.
+func (l List[E]) Reverse() List[E] {
+	var rev List[E]
+	for n := l.head; n != nil; n = n.next {
+		rev = rev.Cons(n.value)
+	}
+	return rev
+}
+
+// Range calls fn for each element of the list from head to tail. Iteration stops early
+// if fn returns false.
+func (l List[E]) Range(fn func(E) bool) {
+	for n := l.head; n != nil; n = n.next {
+		if !fn(n.value) {
+			return
+		}
+	}
+}
+
+// Slice returns a newly allocated slice holding the elements of the list in order.
+// Just like the functions of the immutableslice package, an empty list results in a
+// nil slice.
+func (l List[E]) Slice() []E {
+	if l.head == nil {
+		return nil
+	}
+
+	s := make([]E, 0, l.head.len)
+	for n := l.head; n != nil; n = n.next {
+		s = append(s, n.value)
+	}
+	return s
+}
+
+// Map returns a new list holding the result of calling fn on each element of l.
+func Map[E, F any](l List[E], fn func(E) F) List[F] {
+	if l.head == nil {
+		return List[F]{}
+	}
+
+	// build the list front to back so that fn is called on the elements in order
+	head := &node[F]{value: fn(l.head.value), len: l.head.len}
+	tail := head
+	for n := l.head.next; n != nil; n = n.next {
+		tail.next = &node[F]{value: fn(n.value), len: n.len}
+		tail = tail.next
+	}
+	return List[F]{head: head}
+}
+
+// Filter returns a list holding only the elements of l for which keep returns true.
+// The longest suffix of l for which every element is kept is shared with the returned
+// list instead of being copied.
+func Filter[E any](l List[E], keep func(E) bool) List[E] {
+	// Evaluate the predicate once per element, in order, recording the results.
+	kept := make([]bool, 0, l.Len())
+	shared := l.head
+	for n := l.head; n != nil; n = n.next {
+		k := keep(n.value)
+		kept = append(kept, k)
+		if !k {
+			shared = n.next
+		}
+	}
+
+	// Everything from shared onwards is kept and can be reused as is. The kept
+	// elements before it must be copied onto the front of it in reverse order.
+	result := List[E]{head: shared}
+	var prefix []E
+	i := 0
+	for n := l.head; n != shared; n = n.next {
+		if kept[i] {
+			prefix = append(prefix, n.value)
+		}
+		i += 1
+	}
+
+	for i := len(prefix) - 1; i >= 0; i-- {
+		result = result.Cons(prefix[i])
+	}
+	return result
+}
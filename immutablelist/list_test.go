@@ -0,0 +1,204 @@
+package immutablelist
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	type testCase struct {
+		elems    []int
+		expected []int
+	}
+
+	cases := map[string]testCase{
+		"no elements": {
+			elems:    nil,
+			expected: nil,
+		},
+		"single element": {
+			elems:    []int{1},
+			expected: []int{1},
+		},
+		"many elements": {
+			elems:    []int{1, 2, 3, 4, 5},
+			expected: []int{1, 2, 3, 4, 5},
+		},
+	}
+
+	for name, tcase := range cases {
+		tcase := tcase
+
+		t.Run(name, func(t *testing.T) {
+			t.Run("New", func(t *testing.T) {
+				l := New(tcase.elems...)
+				require.Equal(t, len(tcase.expected), l.Len())
+				require.Equal(t, len(tcase.expected) == 0, l.IsEmpty())
+				require.Equal(t, tcase.expected, l.Slice())
+			})
+
+			t.Run("FromSlice", func(t *testing.T) {
+				l := FromSlice(tcase.elems)
+				require.Equal(t, len(tcase.expected), l.Len())
+				require.Equal(t, tcase.expected, l.Slice())
+			})
+		})
+	}
+}
+
+func TestConsSharesTail(t *testing.T) {
+	tail := New(2, 3)
+	a := tail.Cons(1)
+	b := tail.Cons(42)
+
+	require.Equal(t, []int{1, 2, 3}, a.Slice())
+	require.Equal(t, []int{42, 2, 3}, b.Slice())
+	require.Equal(t, []int{2, 3}, tail.Slice())
+
+	// both new lists must reference the exact same tail nodes
+	require.Same(t, tail.head, a.Tail().head)
+	require.Same(t, tail.head, b.Tail().head)
+}
+
+func TestHeadAndTail(t *testing.T) {
+	var empty List[int]
+
+	_, ok := empty.Head()
+	require.False(t, ok)
+	require.True(t, empty.Tail().IsEmpty())
+
+	l := New(1, 2, 3)
+	head, ok := l.Head()
+	require.True(t, ok)
+	require.Equal(t, 1, head)
+	require.Equal(t, []int{2, 3}, l.Tail().Slice())
+	require.Equal(t, 2, l.Tail().Len())
+}
+
+func TestReverse(t *testing.T) {
+	type testCase struct {
+		list     List[int]
+		expected []int
+	}
+
+	cases := map[string]testCase{
+		"empty list": {
+			list:     New[int](),
+			expected: nil,
+		},
+		"single element": {
+			list:     New(1),
+			expected: []int{1},
+		},
+		"multiple elements": {
+			list:     New(1, 2, 3, 4),
+			expected: []int{4, 3, 2, 1},
+		},
+	}
+
+	for name, tcase := range cases {
+		tcase := tcase
+
+		t.Run(name, func(t *testing.T) {
+			original := tcase.list.Slice()
+
+			actual := tcase.list.Reverse()
+			require.Equal(t, tcase.expected, actual.Slice())
+			require.Equal(t, len(tcase.expected), actual.Len())
+
+			// check the immutability of the input list
+			require.Equal(t, original, tcase.list.Slice())
+		})
+	}
+}
+
+func TestRange(t *testing.T) {
+	l := New(1, 2, 3, 4, 5)
+
+	var firstThree []int
+	l.Range(func(v int) bool {
+		firstThree = append(firstThree, v)
+		return len(firstThree) < 3
+	})
+	require.Equal(t, []int{1, 2, 3}, firstThree)
+}
+
+func TestMap(t *testing.T) {
+	require.True(t, Map(New[int](), strconv.Itoa).IsEmpty())
+
+	l := New(1, 2, 3)
+	mapped := Map(l, strconv.Itoa)
+	require.Equal(t, []string{"1", "2", "3"}, mapped.Slice())
+	require.Equal(t, 3, mapped.Len())
+	require.Equal(t, 2, mapped.Tail().Len())
+}
+
+func TestFilter(t *testing.T) {
+	type testCase struct {
+		list     List[int]
+		keep     func(int) bool
+		expected []int
+		// sharedLen is the length of the suffix of the input that must be reused
+		sharedLen int
+	}
+
+	even := func(v int) bool { return v%2 == 0 }
+
+	cases := map[string]testCase{
+		"empty list": {
+			list:     New[int](),
+			keep:     even,
+			expected: nil,
+		},
+		"keep nothing": {
+			list:     New(1, 3, 5),
+			keep:     even,
+			expected: nil,
+		},
+		"keep everything": {
+			list:      New(2, 4, 6),
+			keep:      even,
+			expected:  []int{2, 4, 6},
+			sharedLen: 3,
+		},
+		"shares kept suffix": {
+			list:      New(1, 2, 3, 4, 6, 8),
+			keep:      even,
+			expected:  []int{2, 4, 6, 8},
+			sharedLen: 3,
+		},
+		"removes last element": {
+			list:     New(2, 4, 5),
+			keep:     even,
+			expected: []int{2, 4},
+		},
+	}
+
+	for name, tcase := range cases {
+		tcase := tcase
+
+		t.Run(name, func(t *testing.T) {
+			original := tcase.list.Slice()
+
+			actual := Filter(tcase.list, tcase.keep)
+			require.Equal(t, tcase.expected, actual.Slice())
+			require.Equal(t, len(tcase.expected), actual.Len())
+			require.Equal(t, original, tcase.list.Slice())
+
+			if tcase.sharedLen > 0 {
+				suffix := tcase.list
+				for suffix.Len() > tcase.sharedLen {
+					suffix = suffix.Tail()
+				}
+
+				shared := actual
+				for shared.Len() > tcase.sharedLen {
+					shared = shared.Tail()
+				}
+				require.Same(t, suffix.head, shared.head)
+			}
+		})
+	}
+}
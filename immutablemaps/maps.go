@@ -0,0 +1,122 @@
+// Package immutablemaps provides functions to operate on plain Go maps in an immutable
+// way. None of the functions modify the maps passed to them. Instead they return newly
+// allocated maps holding the result of the operation.
+//
+// Just like the functions of the immutableslice package, results without any entries
+// are always returned as nil maps.
+package immutablemaps
+
+// Filter will create a new map holding the entries of m for which keep returns true.
+func Filter[M ~map[K]V, K comparable, V any](m M, keep func(K, V) bool) M {
+	var newM M
+	for k, v := range m {
+		if !keep(k, v) {
+			continue
+		}
+
+		if newM == nil {
+			newM = make(M)
+		}
+		newM[k] = v
+	}
+	return newM
+}
+
+// Invert will create a new map with the keys and values of m swapped. When multiple keys
+// of m map to the same value, it is unspecified which of those keys will be kept.
+func Invert[M ~map[K]V, K, V comparable](m M) map[V]K {
+	if len(m) == 0 {
+		return nil
+	}
+
+	newM := make(map[V]K, len(m))
+	for k, v := range m {
+		newM[v] = k
+	}
+	return newM
+}
+
+// MapValues will create a new map with the same keys as m where each value is the
+// result of calling fn with the corresponding entry of m.
+func MapValues[M ~map[K]V, K comparable, V, W any](m M, fn func(K, V) W) map[K]W {
+	if len(m) == 0 {
+		return nil
+	}
+
+	newM := make(map[K]W, len(m))
+	for k, v := range m {
+		newM[k] = fn(k, v)
+	}
+	return newM
+}
+
+// Merge will create a new map holding the entries of all the specified maps. When a key
+// is present in multiple maps, the value from the map specified last is used.
+func Merge[M ~map[K]V, K comparable, V any](ms ...M) M {
+	return MergeFunc(func(_ K, _, incoming V) V { return incoming }, ms...)
+}
+
+// MergeFunc will create a new map holding the entries of all the specified maps. When a
+// key is present in multiple maps, resolve is called with the key, the value merged so
+// far and the value from the map currently being merged, and its result is stored. Maps
+// are merged in the order they were specified.
+func MergeFunc[M ~map[K]V, K comparable, V any](resolve func(key K, existing, incoming V) V, ms ...M) M {
+	size := 0
+	for _, m := range ms {
+		size += len(m)
+	}
+
+	if size == 0 {
+		return nil
+	}
+
+	newM := make(M, size)
+	for _, m := range ms {
+		for k, v := range m {
+			if existing, ok := newM[k]; ok {
+				v = resolve(k, existing, v)
+			}
+			newM[k] = v
+		}
+	}
+	return newM
+}
+
+// Update will create a copy of m where the value of key k is replaced by the result of
+// calling fn. The fn function is passed the current value of k along with whether k is
+// present in m at all.
+func Update[M ~map[K]V, K comparable, V any](m M, k K, fn func(v V, ok bool) V) M {
+	v, ok := m[k]
+	return With(m, k, fn(v, ok))
+}
+
+// With will create a copy of m with the key k set to the value v.
+func With[M ~map[K]V, K comparable, V any](m M, k K, v V) M {
+	newM := make(M, len(m)+1)
+	for key, val := range m {
+		newM[key] = val
+	}
+	newM[k] = v
+	return newM
+}
+
+// Without will create a copy of m with all the specified keys removed.
+func Without[M ~map[K]V, K comparable, V any](m M, keys ...K) M {
+	if len(m) == 0 {
+		return nil
+	}
+
+	newM := make(M, len(m))
+	for k, v := range m {
+		newM[k] = v
+	}
+
+	for _, k := range keys {
+		delete(newM, k)
+	}
+
+	if len(newM) == 0 {
+		return nil
+	}
+	return newM
+}
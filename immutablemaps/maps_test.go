@@ -0,0 +1,272 @@
+package immutablemaps
+
+import (
+	"maps"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// validateResult checks that actual matches expected and that modifying actual does
+// not affect the original input map.
+func validateResult(t *testing.T, input, original, expected, actual map[string]int) {
+	t.Helper()
+
+	// check the correctness of the operation
+	require.Equal(t, expected, actual)
+
+	// check the immutability of the input map
+	if len(expected) == 0 {
+		// when the expected map should have no entries we want to ensure
+		// a proper nil is returned
+		require.Nil(t, actual)
+	} else {
+		// Modify the new map and then check the original to ensure it
+		// is unmodified.
+		actual["modified"] = 42
+		require.Equal(t, original, input)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	type testCase struct {
+		m        map[string]int
+		keep     func(string, int) bool
+		expected map[string]int
+	}
+
+	even := func(_ string, v int) bool { return v%2 == 0 }
+
+	cases := map[string]testCase{
+		"nil map": {
+			m:        nil,
+			keep:     even,
+			expected: nil,
+		},
+		"keep nothing": {
+			m:        map[string]int{"a": 1, "c": 3},
+			keep:     even,
+			expected: nil,
+		},
+		"keep some": {
+			m:        map[string]int{"a": 1, "b": 2, "c": 3, "d": 4},
+			keep:     even,
+			expected: map[string]int{"b": 2, "d": 4},
+		},
+	}
+
+	for name, tcase := range cases {
+		tcase := tcase
+
+		t.Run(name, func(t *testing.T) {
+			// clone the value to isolate any immutability issues to a single test case
+			original := maps.Clone(tcase.m)
+
+			actual := Filter(original, tcase.keep)
+			validateResult(t, original, tcase.m, tcase.expected, actual)
+		})
+	}
+}
+
+func TestInvert(t *testing.T) {
+	require.Nil(t, Invert(map[string]int{}))
+
+	input := map[string]int{"a": 1, "b": 2}
+	require.Equal(t, map[int]string{1: "a", 2: "b"}, Invert(input))
+	require.Equal(t, map[string]int{"a": 1, "b": 2}, input)
+
+	duplicates := Invert(map[string]int{"a": 1, "b": 1})
+	require.Len(t, duplicates, 1)
+	require.Contains(t, []string{"a", "b"}, duplicates[1])
+}
+
+func TestMapValues(t *testing.T) {
+	require.Nil(t, MapValues(map[string]int(nil), func(string, int) bool { return true }))
+
+	input := map[string]int{"a": 1, "b": 2}
+	actual := MapValues(input, func(k string, v int) string {
+		return k + string(rune('0'+v))
+	})
+	require.Equal(t, map[string]string{"a": "a1", "b": "b2"}, actual)
+	require.Equal(t, map[string]int{"a": 1, "b": 2}, input)
+}
+
+func TestMerge(t *testing.T) {
+	type testCase struct {
+		maps     []map[string]int
+		expected map[string]int
+		// expectedSum is the result when merging by summing conflicting values
+		expectedSum map[string]int
+	}
+
+	cases := map[string]testCase{
+		"no maps": {
+			maps:        nil,
+			expected:    nil,
+			expectedSum: nil,
+		},
+		"empty maps": {
+			maps:        []map[string]int{nil, {}},
+			expected:    nil,
+			expectedSum: nil,
+		},
+		"disjoint maps": {
+			maps:        []map[string]int{{"a": 1}, {"b": 2}},
+			expected:    map[string]int{"a": 1, "b": 2},
+			expectedSum: map[string]int{"a": 1, "b": 2},
+		},
+		"overlapping maps": {
+			maps:        []map[string]int{{"a": 1, "b": 2}, {"b": 3}, {"b": 4, "c": 5}},
+			expected:    map[string]int{"a": 1, "b": 4, "c": 5},
+			expectedSum: map[string]int{"a": 1, "b": 9, "c": 5},
+		},
+	}
+
+	for name, tcase := range cases {
+		tcase := tcase
+
+		t.Run(name, func(t *testing.T) {
+			validateMerge := func(t *testing.T, original []map[string]int, expected, actual map[string]int) {
+				t.Helper()
+
+				require.Equal(t, expected, actual)
+				if len(expected) == 0 {
+					require.Nil(t, actual)
+				} else {
+					actual["modified"] = 42
+					for i, m := range original {
+						require.Equal(t, tcase.maps[i], m)
+					}
+				}
+			}
+
+			t.Run("Merge", func(t *testing.T) {
+				var original []map[string]int
+				for _, m := range tcase.maps {
+					original = append(original, maps.Clone(m))
+				}
+
+				actual := Merge(original...)
+				validateMerge(t, original, tcase.expected, actual)
+			})
+
+			t.Run("MergeFunc", func(t *testing.T) {
+				var original []map[string]int
+				for _, m := range tcase.maps {
+					original = append(original, maps.Clone(m))
+				}
+
+				actual := MergeFunc(func(_ string, existing, incoming int) int {
+					return existing + incoming
+				}, original...)
+				validateMerge(t, original, tcase.expectedSum, actual)
+			})
+		})
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	increment := func(v int, ok bool) int {
+		if !ok {
+			return 1
+		}
+		return v + 1
+	}
+
+	input := map[string]int{"a": 1}
+	original := maps.Clone(input)
+
+	validateResult(t, input, original, map[string]int{"a": 2}, Update(input, "a", increment))
+	validateResult(t, input, original, map[string]int{"a": 1, "b": 1}, Update(input, "b", increment))
+	require.Equal(t, map[string]int{"a": 1}, Update(map[string]int(nil), "a", increment))
+}
+
+func TestWith(t *testing.T) {
+	type testCase struct {
+		m        map[string]int
+		key      string
+		value    int
+		expected map[string]int
+	}
+
+	cases := map[string]testCase{
+		"nil map": {
+			m:        nil,
+			key:      "a",
+			value:    1,
+			expected: map[string]int{"a": 1},
+		},
+		"add key": {
+			m:        map[string]int{"a": 1},
+			key:      "b",
+			value:    2,
+			expected: map[string]int{"a": 1, "b": 2},
+		},
+		"replace key": {
+			m:        map[string]int{"a": 1},
+			key:      "a",
+			value:    2,
+			expected: map[string]int{"a": 2},
+		},
+	}
+
+	for name, tcase := range cases {
+		tcase := tcase
+
+		t.Run(name, func(t *testing.T) {
+			// clone the value to isolate any immutability issues to a single test case
+			original := maps.Clone(tcase.m)
+
+			actual := With(original, tcase.key, tcase.value)
+			validateResult(t, original, tcase.m, tcase.expected, actual)
+		})
+	}
+}
+
+func TestWithout(t *testing.T) {
+	type testCase struct {
+		m        map[string]int
+		keys     []string
+		expected map[string]int
+	}
+
+	cases := map[string]testCase{
+		"nil map": {
+			m:        nil,
+			keys:     []string{"a"},
+			expected: nil,
+		},
+		"remove nothing": {
+			m:        map[string]int{"a": 1},
+			keys:     nil,
+			expected: map[string]int{"a": 1},
+		},
+		"remove missing key": {
+			m:        map[string]int{"a": 1},
+			keys:     []string{"b"},
+			expected: map[string]int{"a": 1},
+		},
+		"remove some": {
+			m:        map[string]int{"a": 1, "b": 2, "c": 3},
+			keys:     []string{"a", "c"},
+			expected: map[string]int{"b": 2},
+		},
+		"remove all": {
+			m:        map[string]int{"a": 1, "b": 2},
+			keys:     []string{"a", "b"},
+			expected: nil,
+		},
+	}
+
+	for name, tcase := range cases {
+		tcase := tcase
+
+		t.Run(name, func(t *testing.T) {
+			// clone the value to isolate any immutability issues to a single test case
+			original := maps.Clone(tcase.m)
+
+			actual := Without(original, tcase.keys...)
+			validateResult(t, original, tcase.m, tcase.expected, actual)
+		})
+	}
+}
@@ -0,0 +1,347 @@
+// Package immutableobservable provides a holder for an immutable slice which publishes a
+// change event to its subscribers whenever a new version of the slice is produced.
+package immutableobservable
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/mkeeler/go-immutable/immutableslice"
+)
+
+// Kind describes how a slice changed.
+type Kind int
+
+const (
+	// Inserted means that elements were inserted into the slice.
+	Inserted Kind = iota + 1
+	// Deleted means that elements were removed from the slice.
+	Deleted
+	// Replaced means that a range of elements was replaced by other elements.
+	Replaced
+	// Reordered means that the elements of the slice were rearranged.
+	Reordered
+)
+
+// String returns the name of the kind.
+func (k Kind) String() string {
+	switch k {
+	case Inserted:
+		return "inserted"
+	case Deleted:
+		return "deleted"
+	case Replaced:
+		return "replaced"
+	case Reordered:
+		return "reordered"
+	default:
+		return fmt.Sprintf("Kind(%d)", int(k))
+	}
+}
+
+// Event describes a single change of an observed slice. Removed elements starting at
+// Index of the Old slice were replaced by Added elements starting at Index of the New
+// slice, so the elements removed were Old[Index:Index+Removed] and the elements added
+// are New[Index:Index+Added]. For Reordered events the whole slice is affected.
+//
+// The Old and New slices must not be modified.
+type Event[S ~[]E, E any] struct {
+	Kind    Kind
+	Index   int
+	Removed int
+	Added   int
+
+	Old S
+	New S
+
+	// Version is the version of the New slice. The initial slice has version zero and
+	// every change increments the version by one.
+	Version uint64
+
+	// Dropped is the number of events that were not delivered to the subscriber due
+	// to its backpressure policy. With DropNewest these are the events right before
+	// this one. With DropOldest the events are dropped from the front of the buffer,
+	// so their count is carried by a later event. In both cases the Dropped fields of
+	// all received events add up to the total number of missed events.
+	Dropped int
+}
+
+// Policy decides what happens when an event is published to a channel subscriber whose
+// channel buffer is full.
+type Policy int
+
+const (
+	// Block waits until the subscriber receives from the channel. This slows down all
+	// edits of the observable to the pace of the slowest subscriber.
+	Block Policy = iota
+	// DropOldest discards the oldest event in the channel buffer to make room for the
+	// new event.
+	DropOldest
+	// DropNewest discards the new event.
+	DropNewest
+)
+
+// subscriber is either a callback or a channel subscription.
+type subscriber[S ~[]E, E any] struct {
+	fn func(Event[S, E])
+
+	ch      chan Event[S, E]
+	policy  Policy
+	dropped int
+
+	// done is closed once the subscription is canceled. For channel subscriptions mu
+	// guards sending on and closing ch.
+	done   chan struct{}
+	cancel sync.Once
+	mu     sync.Mutex
+	closed bool
+}
+
+// Observable holds the current version of a slice. Its edit methods produce a new
+// version of the slice with the functions of the immutableslice package, make it current
+// and then publish an Event describing the change to every subscriber. Edits which do
+// not change the slice, such as deleting an empty range, publish no event.
+//
+// Events are published in the order the edits happened, and all subscribers have
+// received an event before the edit method returns, unless a subscriber dropped it
+// according to its Policy. Subscribers must not edit the observable synchronously from
+// within the delivery of an event as that would deadlock.
+//
+// Observables must be created with New. An Observable is safe for concurrent use by
+// multiple goroutines.
+type Observable[S ~[]E, E any] struct {
+	mu      sync.Mutex
+	value   S
+	version uint64
+	subs    []*subscriber[S, E]
+
+	// notifyMu is held while publishing an event so that events are delivered in
+	// order without blocking Load during delivery.
+	notifyMu sync.Mutex
+}
+
+// New creates an observable whose initial version is the specified slice. The slice must
+// not be modified afterwards.
+func New[S ~[]E, E any](initial S) *Observable[S, E] {
+	return &Observable[S, E]{value: initial}
+}
+
+// Load returns the current version of the slice. The slice must not be modified.
+func (o *Observable[S, E]) Load() S {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.value
+}
+
+// Version returns the number of changes made to the slice so far.
+func (o *Observable[S, E]) Version() uint64 {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.version
+}
+
+// Subscribe registers fn to be called with every subsequent event. The returned function
+// cancels the subscription, after which fn is no longer called. Events are delivered
+// synchronously, so a slow callback delays every edit.
+func (o *Observable[S, E]) Subscribe(fn func(Event[S, E])) (cancel func()) {
+	return o.subscribe(&subscriber[S, E]{fn: fn, done: make(chan struct{})})
+}
+
+// SubscribeChan returns a channel receiving every subsequent event. The channel buffers
+// up to size events and the policy decides what happens when it is full. Dropped events
+// are accounted for in the Dropped field of the events that are delivered. The
+// returned function cancels the subscription and closes the channel.
+func (o *Observable[S, E]) SubscribeChan(size int, policy Policy) (<-chan Event[S, E], func()) {
+	sub := &subscriber[S, E]{
+		ch:     make(chan Event[S, E], size),
+		policy: policy,
+		done:   make(chan struct{}),
+	}
+	return sub.ch, o.subscribe(sub)
+}
+
+// Insert inserts the values v at index i and returns the new slice. It panics if i is
+// out of range.
+func (o *Observable[S, E]) Insert(i int, v ...E) S {
+	return o.edit(func(s S) (S, Event[S, E]) {
+		return immutableslice.Insert(s, i, v...), Event[S, E]{Kind: Inserted, Index: i, Added: len(v)}
+	})
+}
+
+// Append appends the values v and returns the new slice.
+func (o *Observable[S, E]) Append(v ...E) S {
+	return o.edit(func(s S) (S, Event[S, E]) {
+		return immutableslice.Append(s, v...), Event[S, E]{Kind: Inserted, Index: len(s), Added: len(v)}
+	})
+}
+
+// Delete removes the elements s[i:j] and returns the new slice. It panics if s[i:j] is
+// not a valid slice of the current slice s.
+func (o *Observable[S, E]) Delete(i, j int) S {
+	return o.edit(func(s S) (S, Event[S, E]) {
+		checkRange(s, i, j)
+		return immutableslice.Delete(s, i, j), Event[S, E]{Kind: Deleted, Index: i, Removed: j - i}
+	})
+}
+
+// Replace replaces the elements s[i:j] with the values v and returns the new slice. It
+// panics if s[i:j] is not a valid slice of the current slice s.
+func (o *Observable[S, E]) Replace(i, j int, v ...E) S {
+	return o.edit(func(s S) (S, Event[S, E]) {
+		checkRange(s, i, j)
+		return immutableslice.Replace(s, i, j, v...), replaceEvent[S](i, j-i, len(v))
+	})
+}
+
+// Set replaces the whole slice with s and returns it. The slice must not be modified
+// afterwards.
+func (o *Observable[S, E]) Set(s S) S {
+	return o.edit(func(old S) (S, Event[S, E]) {
+		return s, replaceEvent[S](0, len(old), len(s))
+	})
+}
+
+// SortFunc sorts the slice in ascending order as determined by the cmp function and
+// returns the new slice.
+func (o *Observable[S, E]) SortFunc(cmp func(a, b E) int) S {
+	return o.edit(func(s S) (S, Event[S, E]) {
+		return immutableslice.SortFunc(s, cmp), Event[S, E]{Kind: Reordered, Removed: len(s), Added: len(s)}
+	})
+}
+
+// replaceEvent creates the event for replacing removed elements at index i by added
+// elements, which is reported as an insertion or deletion when nothing was removed or
+// added respectively.
+func replaceEvent[S ~[]E, E any](i, removed, added int) Event[S, E] {
+	kind := Replaced
+	switch {
+	case removed == 0:
+		kind = Inserted
+	case added == 0:
+		kind = Deleted
+	}
+	return Event[S, E]{Kind: kind, Index: i, Removed: removed, Added: added}
+}
+
+// checkRange panics if s[i:j] is not a valid slice of s.
+func checkRange[S ~[]E, E any](s S, i, j int) {
+	_ = s[i:j]
+}
+
+// edit makes the slice produced by fn current and publishes the event describing the
+// change to all subscribers.
+func (o *Observable[S, E]) edit(fn func(S) (S, Event[S, E])) S {
+	value, ev, subs, changed := o.update(fn)
+	if !changed {
+		return value
+	}
+	defer o.notifyMu.Unlock()
+
+	for _, sub := range subs {
+		sub.deliver(ev)
+	}
+	return value
+}
+
+// update makes the slice produced by fn current and returns it along with the event to
+// publish and the subscribers to publish it to. If the slice changed, update returns
+// with the notification lock held, which must be released once the event is published.
+func (o *Observable[S, E]) update(fn func(S) (S, Event[S, E])) (S, Event[S, E], []*subscriber[S, E], bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	old := o.value
+	value, ev := fn(old)
+	if ev.Removed == 0 && ev.Added == 0 {
+		return old, ev, nil, false
+	}
+
+	o.value = value
+	o.version += 1
+	ev.Old, ev.New, ev.Version = old, value, o.version
+
+	// acquire the notification lock before releasing the state lock so that the
+	// events of concurrent edits are published in the order the edits happened
+	o.notifyMu.Lock()
+	return value, ev, o.subs, true
+}
+
+func (o *Observable[S, E]) subscribe(sub *subscriber[S, E]) func() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	// subs is never modified in place so that edits can publish to a snapshot of it
+	o.subs = immutableslice.Append(o.subs, sub)
+
+	return func() {
+		sub.cancel.Do(func() {
+			close(sub.done)
+
+			o.mu.Lock()
+			o.subs = immutableslice.DeleteFunc(o.subs, func(s *subscriber[S, E]) bool { return s == sub })
+			o.mu.Unlock()
+
+			if sub.ch != nil {
+				sub.mu.Lock()
+				sub.closed = true
+				close(sub.ch)
+				sub.mu.Unlock()
+			}
+		})
+	}
+}
+
+func (s *subscriber[S, E]) deliver(ev Event[S, E]) {
+	select {
+	case <-s.done:
+		return
+	default:
+	}
+
+	if s.fn != nil {
+		s.fn(ev)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+
+	ev.Dropped = s.dropped
+	select {
+	case s.ch <- ev:
+		s.dropped = 0
+		return
+	default:
+	}
+
+	switch s.policy {
+	case DropNewest:
+		s.dropped += 1
+	case DropOldest:
+		select {
+		case old := <-s.ch:
+			// the dropped event may itself have carried the count of events
+			// dropped before it
+			s.dropped += old.Dropped + 1
+		default:
+			// the subscriber received an event in the meantime
+		}
+
+		ev.Dropped = s.dropped
+		select {
+		case s.ch <- ev:
+			s.dropped = 0
+		default:
+			// unbuffered channels have no oldest event to drop
+			s.dropped += 1
+		}
+	default:
+		select {
+		case s.ch <- ev:
+			s.dropped = 0
+		case <-s.done:
+		}
+	}
+}
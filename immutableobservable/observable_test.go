@@ -0,0 +1,289 @@
+package immutableobservable
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEdits(t *testing.T) {
+	type testCase struct {
+		edit     func(o *Observable[[]string, string]) []string
+		expected []string
+		event    Event[[]string, string]
+	}
+
+	cases := map[string]testCase{
+		"Insert": {
+			edit:     func(o *Observable[[]string, string]) []string { return o.Insert(1, "x", "y") },
+			expected: []string{"c", "x", "y", "a", "b"},
+			event:    Event[[]string, string]{Kind: Inserted, Index: 1, Added: 2},
+		},
+		"Append": {
+			edit:     func(o *Observable[[]string, string]) []string { return o.Append("x") },
+			expected: []string{"c", "a", "b", "x"},
+			event:    Event[[]string, string]{Kind: Inserted, Index: 3, Added: 1},
+		},
+		"Delete": {
+			edit:     func(o *Observable[[]string, string]) []string { return o.Delete(0, 2) },
+			expected: []string{"b"},
+			event:    Event[[]string, string]{Kind: Deleted, Index: 0, Removed: 2},
+		},
+		"Replace": {
+			edit:     func(o *Observable[[]string, string]) []string { return o.Replace(1, 2, "x", "y") },
+			expected: []string{"c", "x", "y", "b"},
+			event:    Event[[]string, string]{Kind: Replaced, Index: 1, Removed: 1, Added: 2},
+		},
+		"Replace without values": {
+			edit:     func(o *Observable[[]string, string]) []string { return o.Replace(1, 3) },
+			expected: []string{"c"},
+			event:    Event[[]string, string]{Kind: Deleted, Index: 1, Removed: 2},
+		},
+		"Set": {
+			edit:     func(o *Observable[[]string, string]) []string { return o.Set([]string{"z"}) },
+			expected: []string{"z"},
+			event:    Event[[]string, string]{Kind: Replaced, Index: 0, Removed: 3, Added: 1},
+		},
+		"SortFunc": {
+			edit:     func(o *Observable[[]string, string]) []string { return o.SortFunc(strings.Compare) },
+			expected: []string{"a", "b", "c"},
+			event:    Event[[]string, string]{Kind: Reordered, Index: 0, Removed: 3, Added: 3},
+		},
+	}
+
+	for name, tcase := range cases {
+		tcase := tcase
+
+		t.Run(name, func(t *testing.T) {
+			initial := []string{"c", "a", "b"}
+			o := New(initial)
+
+			var events []Event[[]string, string]
+			o.Subscribe(func(ev Event[[]string, string]) {
+				events = append(events, ev)
+			})
+
+			actual := tcase.edit(o)
+			require.Equal(t, tcase.expected, actual)
+			require.Equal(t, tcase.expected, o.Load())
+			require.Equal(t, uint64(1), o.Version())
+			require.Equal(t, []string{"c", "a", "b"}, initial)
+
+			expected := tcase.event
+			expected.Old = initial
+			expected.New = tcase.expected
+			expected.Version = 1
+			require.Equal(t, []Event[[]string, string]{expected}, events)
+
+			ev := events[0]
+			require.Equal(t,
+				initial[ev.Index+ev.Removed:],
+				ev.New[ev.Index+ev.Added:],
+				"the elements after the affected ranges are the same")
+		})
+	}
+}
+
+func TestNoopEdits(t *testing.T) {
+	o := New([]int{1, 2})
+
+	called := false
+	o.Subscribe(func(Event[[]int, int]) { called = true })
+
+	o.Insert(1)
+	o.Delete(1, 1)
+	o.Replace(2, 2)
+	require.False(t, called)
+	require.Equal(t, uint64(0), o.Version())
+
+	require.Panics(t, func() { o.Delete(2, 1) })
+	require.Panics(t, func() { o.Replace(1, 3, 5) })
+	require.Panics(t, func() { New([]int(nil)).Delete(0, 1) })
+}
+
+func TestCancel(t *testing.T) {
+	o := New([]int(nil))
+
+	var first, second int
+	cancelFirst := o.Subscribe(func(Event[[]int, int]) { first += 1 })
+	o.Subscribe(func(Event[[]int, int]) { second += 1 })
+	ch, cancelChan := o.SubscribeChan(10, Block)
+
+	o.Append(1)
+	cancelFirst()
+	cancelFirst()
+	o.Append(2)
+	cancelChan()
+
+	require.Equal(t, 1, first)
+	require.Equal(t, 2, second)
+
+	var received []int
+	for ev := range ch {
+		received = append(received, ev.New...)
+	}
+	require.Equal(t, []int{1, 1, 2}, received)
+}
+
+func TestCancelFromCallback(t *testing.T) {
+	o := New([]int(nil))
+
+	calls := 0
+	var cancel func()
+	cancel = o.Subscribe(func(Event[[]int, int]) {
+		calls += 1
+		cancel()
+	})
+
+	o.Append(1)
+	o.Append(2)
+	require.Equal(t, 1, calls)
+}
+
+func TestBackpressure(t *testing.T) {
+	type testCase struct {
+		policy   Policy
+		versions []uint64
+		dropped  []int
+	}
+
+	cases := map[string]testCase{
+		"DropNewest": {
+			policy:   DropNewest,
+			versions: []uint64{1, 2, 6},
+			dropped:  []int{0, 0, 3},
+		},
+		"DropOldest": {
+			policy:   DropOldest,
+			versions: []uint64{4, 5, 6},
+			dropped:  []int{1, 2, 0},
+		},
+	}
+
+	for name, tcase := range cases {
+		tcase := tcase
+
+		t.Run(name, func(t *testing.T) {
+			o := New([]int(nil))
+			ch, cancel := o.SubscribeChan(2, tcase.policy)
+			defer cancel()
+
+			// nothing receives from the channel while the first five edits happen
+			for i := 0; i < 5; i++ {
+				o.Append(i)
+			}
+
+			var versions []uint64
+			var dropped []int
+			receive := func() {
+				ev := <-ch
+				versions = append(versions, ev.Version)
+				dropped = append(dropped, ev.Dropped)
+			}
+
+			receive()
+			receive()
+			o.Append(5)
+			receive()
+
+			require.Equal(t, tcase.versions, versions)
+			require.Equal(t, tcase.dropped, dropped)
+			require.Len(t, o.Load(), 6)
+		})
+	}
+}
+
+func TestBackpressureUnbuffered(t *testing.T) {
+	o := New([]int(nil))
+	ch, cancel := o.SubscribeChan(0, DropOldest)
+	defer cancel()
+
+	received := make(chan Event[[]int, int])
+	go func() {
+		received <- <-ch
+	}()
+
+	// without a buffer, events are only delivered while the receiver is waiting
+	for i := 0; ; i++ {
+		o.Append(i)
+		select {
+		case ev := <-received:
+			require.Equal(t, int(ev.Version)-1, ev.Dropped)
+			return
+		default:
+		}
+	}
+}
+
+func TestBlockingSubscriber(t *testing.T) {
+	o := New([]int(nil))
+	ch, cancel := o.SubscribeChan(0, Block)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			o.Append(i)
+		}
+	}()
+
+	var versions []uint64
+	for ev := range ch {
+		versions = append(versions, ev.Version)
+		require.Zero(t, ev.Dropped)
+		if ev.Version == 50 {
+			break
+		}
+	}
+
+	// canceling unblocks the edits waiting on the subscriber
+	cancel()
+	wg.Wait()
+
+	require.Len(t, versions, 50)
+	for i, v := range versions {
+		require.Equal(t, uint64(i+1), v)
+	}
+	require.Len(t, o.Load(), 100)
+}
+
+func TestConcurrentEditsOrdered(t *testing.T) {
+	o := New([]int(nil))
+
+	var mu sync.Mutex
+	var versions []uint64
+	var lengths []int
+	o.Subscribe(func(ev Event[[]int, int]) {
+		mu.Lock()
+		defer mu.Unlock()
+		versions = append(versions, ev.Version)
+		lengths = append(lengths, len(ev.New))
+	})
+
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				o.Append(i)
+			}
+		}()
+	}
+	wg.Wait()
+
+	require.Len(t, versions, 400)
+	for i, v := range versions {
+		require.Equal(t, uint64(i+1), v)
+		require.Equal(t, i+1, lengths[i])
+	}
+}
+
+func TestKindString(t *testing.T) {
+	require.Equal(t, "inserted", Inserted.String())
+	require.Equal(t, "reordered", Reordered.String())
+	require.Equal(t, "Kind(0)", Kind(0).String())
+}
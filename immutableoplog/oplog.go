@@ -0,0 +1,294 @@
+// Package immutableoplog provides serializable descriptions of slice edits which can be
+// persisted as an audit log and replayed with the functions of the immutableslice
+// package. Every recorded operation carries a checksum of the slice it produced, so a
+// replay detects as soon as it diverges from the original sequence of edits.
+package immutableoplog
+
+import (
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+
+	"github.com/mkeeler/go-immutable/immutableslice"
+)
+
+var (
+	// ErrChecksumMismatch is returned by Replay when the slice produced by an operation
+	// does not match the checksum recorded for it.
+	ErrChecksumMismatch = errors.New("immutableoplog: checksum mismatch")
+
+	// ErrUnknownFunc is returned when an operation refers to a function name which is
+	// not present in the registry.
+	ErrUnknownFunc = errors.New("immutableoplog: unknown function")
+
+	// ErrInvalidOp is returned when an operation has an unknown kind or indexes which
+	// are out of range for the slice it is applied to.
+	ErrInvalidOp = errors.New("immutableoplog: invalid operation")
+)
+
+// Kind identifies the immutableslice function an operation corresponds to.
+type Kind string
+
+// The kinds of operations, named after the immutableslice function they correspond to.
+const (
+	KindAppend     Kind = "append"
+	KindPrepend    Kind = "prepend"
+	KindInsert     Kind = "insert"
+	KindDelete     Kind = "delete"
+	KindDeleteFunc Kind = "deleteFunc"
+	KindReplace    Kind = "replace"
+	KindSort       Kind = "sort"
+	KindReverse    Kind = "reverse"
+	KindCompact    Kind = "compact"
+)
+
+// Op describes a single edit of a slice. Functions can not be serialized, so operations
+// needing one refer to it by the name it was registered with in a Registry.
+//
+// Op values encode to JSON with lower case field names and to a binary format with
+// Encoder. The element type must be encodable by encoding/json and encoding/gob.
+type Op[E any] struct {
+	Kind Kind `json:"kind"`
+	// I and J are the bounds of the range of elements the operation affects for
+	// Insert, Delete and Replace operations.
+	I int `json:"i,omitempty"`
+	J int `json:"j,omitempty"`
+	// Values are the elements added by Append, Prepend, Insert and Replace operations.
+	Values []E `json:"values,omitempty"`
+	// Func is the name of the predicate used by DeleteFunc operations and of the
+	// comparison function used by Sort and Compact operations.
+	Func string `json:"func,omitempty"`
+	// Checksum is the checksum of the slice produced by the operation as computed by
+	// Checksum. It is set by Record.
+	Checksum uint64 `json:"checksum,omitempty"`
+}
+
+// Append creates an operation appending the values v, see immutableslice.Append.
+func Append[E any](v ...E) Op[E] {
+	return Op[E]{Kind: KindAppend, Values: v}
+}
+
+// Prepend creates an operation prepending the values v, see immutableslice.Prepend.
+func Prepend[E any](v ...E) Op[E] {
+	return Op[E]{Kind: KindPrepend, Values: v}
+}
+
+// Insert creates an operation inserting the values v at index i, see
+// immutableslice.Insert.
+func Insert[E any](i int, v ...E) Op[E] {
+	return Op[E]{Kind: KindInsert, I: i, Values: v}
+}
+
+// Delete creates an operation deleting the elements s[i:j], see immutableslice.Delete.
+func Delete[E any](i, j int) Op[E] {
+	return Op[E]{Kind: KindDelete, I: i, J: j}
+}
+
+// DeleteFunc creates an operation deleting the elements for which the predicate
+// registered as name returns true, see immutableslice.DeleteFunc.
+func DeleteFunc[E any](name string) Op[E] {
+	return Op[E]{Kind: KindDeleteFunc, Func: name}
+}
+
+// Replace creates an operation replacing the elements s[i:j] with the values v, see
+// immutableslice.Replace.
+func Replace[E any](i, j int, v ...E) Op[E] {
+	return Op[E]{Kind: KindReplace, I: i, J: j, Values: v}
+}
+
+// Sort creates an operation sorting the elements with the comparison function
+// registered as name. Sorting is stable so that the result is deterministic, see
+// immutableslice.SortStableFunc.
+func Sort[E any](name string) Op[E] {
+	return Op[E]{Kind: KindSort, Func: name}
+}
+
+// Reverse creates an operation reversing the elements, see immutableslice.Reverse.
+func Reverse[E any]() Op[E] {
+	return Op[E]{Kind: KindReverse}
+}
+
+// Compact creates an operation replacing consecutive runs of elements for which the
+// comparison function registered as name returns zero with the first instance, see
+// immutableslice.CompactFunc.
+func Compact[E any](name string) Op[E] {
+	return Op[E]{Kind: KindCompact, Func: name}
+}
+
+// Registry holds the named functions operations may refer to. The zero value of a
+// Registry is empty and ready to use. A Registry must not be modified while operations
+// are applied with it.
+type Registry[E any] struct {
+	predicates  map[string]func(E) bool
+	comparators map[string]func(a, b E) int
+}
+
+// RegisterPredicate registers fn under the specified name for use by DeleteFunc
+// operations. It panics if a predicate with the same name was already registered.
+func (r *Registry[E]) RegisterPredicate(name string, fn func(E) bool) {
+	if _, ok := r.predicates[name]; ok {
+		panic(fmt.Sprintf("immutableoplog: predicate %q already registered", name))
+	}
+	if r.predicates == nil {
+		r.predicates = make(map[string]func(E) bool)
+	}
+	r.predicates[name] = fn
+}
+
+// RegisterComparator registers the comparison function fn under the specified name for
+// use by Sort and Compact operations. It panics if a comparison function with the same
+// name was already registered.
+func (r *Registry[E]) RegisterComparator(name string, fn func(a, b E) int) {
+	if _, ok := r.comparators[name]; ok {
+		panic(fmt.Sprintf("immutableoplog: comparator %q already registered", name))
+	}
+	if r.comparators == nil {
+		r.comparators = make(map[string]func(a, b E) int)
+	}
+	r.comparators[name] = fn
+}
+
+// Apply applies the single operation op to s and returns the resulting slice. The
+// registry may be nil when op does not refer to a named function. The recorded checksum
+// of op is not verified.
+func Apply[S ~[]E, E any](s S, op Op[E], reg *Registry[E]) (S, error) {
+	switch op.Kind {
+	case KindAppend:
+		return immutableslice.Append(s, op.Values...), nil
+	case KindPrepend:
+		return immutableslice.Prepend(s, op.Values...), nil
+	case KindInsert:
+		if op.I < 0 || op.I > len(s) {
+			return nil, fmt.Errorf("%w: insert index %d out of range [0:%d]", ErrInvalidOp, op.I, len(s))
+		}
+		return immutableslice.Insert(s, op.I, op.Values...), nil
+	case KindDelete, KindReplace:
+		if op.I < 0 || op.I > op.J || op.J > len(s) {
+			return nil, fmt.Errorf("%w: %s range [%d:%d] out of range [0:%d]", ErrInvalidOp, op.Kind, op.I, op.J, len(s))
+		}
+		if op.Kind == KindDelete {
+			return immutableslice.Delete(s, op.I, op.J), nil
+		}
+		return immutableslice.Replace(s, op.I, op.J, op.Values...), nil
+	case KindDeleteFunc:
+		var fn func(E) bool
+		if reg != nil {
+			fn = reg.predicates[op.Func]
+		}
+		if fn == nil {
+			return nil, fmt.Errorf("%w: predicate %q", ErrUnknownFunc, op.Func)
+		}
+		return immutableslice.DeleteFunc(s, fn), nil
+	case KindSort, KindCompact:
+		var fn func(a, b E) int
+		if reg != nil {
+			fn = reg.comparators[op.Func]
+		}
+		if fn == nil {
+			return nil, fmt.Errorf("%w: comparator %q", ErrUnknownFunc, op.Func)
+		}
+		if op.Kind == KindSort {
+			return immutableslice.SortStableFunc(s, fn), nil
+		}
+		return immutableslice.CompactFunc(s, func(a, b E) bool { return fn(a, b) == 0 }), nil
+	case KindReverse:
+		return immutableslice.Reverse(s), nil
+	default:
+		return nil, fmt.Errorf("%w: unknown kind %q", ErrInvalidOp, op.Kind)
+	}
+}
+
+// Record applies the operations to base in order and returns copies of the operations
+// with their Checksum set to the checksum of the slice each of them produced, along
+// with the final slice.
+func Record[S ~[]E, E any](base S, ops []Op[E], reg *Registry[E]) ([]Op[E], S, error) {
+	recorded := make([]Op[E], len(ops))
+	s := base
+	for i, op := range ops {
+		var err error
+		s, err = Apply(s, op, reg)
+		if err != nil {
+			return nil, nil, fmt.Errorf("step %d: %w", i, err)
+		}
+
+		op.Checksum, err = Checksum(s)
+		if err != nil {
+			return nil, nil, fmt.Errorf("step %d: %w", i, err)
+		}
+		recorded[i] = op
+	}
+	return recorded, s, nil
+}
+
+// Replay applies the operations to base in order and returns the final slice. After
+// each step the checksum of the intermediate slice is compared to the checksum recorded
+// in the operation and ErrChecksumMismatch is returned on the first difference.
+func Replay[S ~[]E, E any](base S, ops []Op[E], reg *Registry[E]) (S, error) {
+	s := base
+	for i, op := range ops {
+		var err error
+		s, err = Apply(s, op, reg)
+		if err != nil {
+			return nil, fmt.Errorf("step %d: %w", i, err)
+		}
+
+		sum, err := Checksum(s)
+		if err != nil {
+			return nil, fmt.Errorf("step %d: %w", i, err)
+		}
+		if sum != op.Checksum {
+			return nil, fmt.Errorf("step %d: %w: expected %x, got %x", i, ErrChecksumMismatch, op.Checksum, sum)
+		}
+	}
+	return s, nil
+}
+
+// Checksum computes the 64-bit FNV-1a hash of the JSON encoding of s. Empty and nil
+// slices have the same checksum.
+func Checksum[S ~[]E, E any](s S) (uint64, error) {
+	h := fnv.New64a()
+	if len(s) > 0 {
+		if err := json.NewEncoder(h).Encode(s); err != nil {
+			return 0, err
+		}
+	}
+	return h.Sum64(), nil
+}
+
+// Encoder writes operations to a stream in a compact binary format based on
+// encoding/gob. Type information is written only once per stream, so all operations of
+// a log should be written with the same Encoder.
+type Encoder[E any] struct {
+	enc *gob.Encoder
+}
+
+// NewEncoder creates an encoder writing to w.
+func NewEncoder[E any](w io.Writer) *Encoder[E] {
+	return &Encoder[E]{enc: gob.NewEncoder(w)}
+}
+
+// Encode writes op to the stream.
+func (e *Encoder[E]) Encode(op Op[E]) error {
+	return e.enc.Encode(op)
+}
+
+// Decoder reads operations written by an Encoder.
+type Decoder[E any] struct {
+	dec *gob.Decoder
+}
+
+// NewDecoder creates a decoder reading from r.
+func NewDecoder[E any](r io.Reader) *Decoder[E] {
+	return &Decoder[E]{dec: gob.NewDecoder(r)}
+}
+
+// Decode reads the next operation from the stream. It returns io.EOF when the stream
+// holds no more operations.
+func (d *Decoder[E]) Decode() (Op[E], error) {
+	var op Op[E]
+	err := d.dec.Decode(&op)
+	return op, err
+}
@@ -0,0 +1,228 @@
+package immutableoplog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func registry() *Registry[string] {
+	var reg Registry[string]
+	reg.RegisterPredicate("empty", func(s string) bool { return s == "" })
+	reg.RegisterComparator("alpha", strings.Compare)
+	reg.RegisterComparator("fold", func(a, b string) int {
+		return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+	})
+	return &reg
+}
+
+func TestApply(t *testing.T) {
+	base := []string{"b", "", "a", "A", "c"}
+
+	type testCase struct {
+		op       Op[string]
+		expected []string
+		err      error
+	}
+
+	cases := map[string]testCase{
+		"Append": {
+			op:       Append("x", "y"),
+			expected: []string{"b", "", "a", "A", "c", "x", "y"},
+		},
+		"Prepend": {
+			op:       Prepend("x"),
+			expected: []string{"x", "b", "", "a", "A", "c"},
+		},
+		"Insert": {
+			op:       Insert(2, "x"),
+			expected: []string{"b", "", "x", "a", "A", "c"},
+		},
+		"Insert out of range": {
+			op:  Insert(6, "x"),
+			err: ErrInvalidOp,
+		},
+		"Delete": {
+			op:       Delete[string](1, 3),
+			expected: []string{"b", "A", "c"},
+		},
+		"Delete invalid range": {
+			op:  Delete[string](3, 1),
+			err: ErrInvalidOp,
+		},
+		"DeleteFunc": {
+			op:       DeleteFunc[string]("empty"),
+			expected: []string{"b", "a", "A", "c"},
+		},
+		"DeleteFunc unknown predicate": {
+			op:  DeleteFunc[string]("missing"),
+			err: ErrUnknownFunc,
+		},
+		"Replace": {
+			op:       Replace(0, 2, "z"),
+			expected: []string{"z", "a", "A", "c"},
+		},
+		"Replace out of range": {
+			op:  Replace(4, 6, "z"),
+			err: ErrInvalidOp,
+		},
+		"Sort": {
+			op:       Sort[string]("fold"),
+			expected: []string{"", "a", "A", "b", "c"},
+		},
+		"Sort unknown comparator": {
+			op:  Sort[string]("missing"),
+			err: ErrUnknownFunc,
+		},
+		"Reverse": {
+			op:       Reverse[string](),
+			expected: []string{"c", "A", "a", "", "b"},
+		},
+		"Compact": {
+			op:       Compact[string]("fold"),
+			expected: []string{"b", "", "a", "c"},
+		},
+		"unknown kind": {
+			op:  Op[string]{Kind: "shuffle"},
+			err: ErrInvalidOp,
+		},
+	}
+
+	for name, tcase := range cases {
+		tcase := tcase
+
+		t.Run(name, func(t *testing.T) {
+			actual, err := Apply(base, tcase.op, registry())
+			if tcase.err != nil {
+				require.ErrorIs(t, err, tcase.err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tcase.expected, actual)
+			require.Equal(t, []string{"b", "", "a", "A", "c"}, base)
+		})
+	}
+}
+
+func TestApplyNilRegistry(t *testing.T) {
+	_, err := Apply([]string{"a"}, Sort[string]("alpha"), nil)
+	require.ErrorIs(t, err, ErrUnknownFunc)
+
+	actual, err := Apply([]string{"a"}, Append("b"), nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b"}, actual)
+}
+
+func TestRegistryDuplicates(t *testing.T) {
+	reg := registry()
+	require.Panics(t, func() { reg.RegisterPredicate("empty", nil) })
+	require.Panics(t, func() { reg.RegisterComparator("alpha", nil) })
+}
+
+func TestRecordReplay(t *testing.T) {
+	base := []string{"b", "a"}
+	ops := []Op[string]{
+		Append("", "c"),
+		DeleteFunc[string]("empty"),
+		Sort[string]("alpha"),
+		Insert(1, "a"),
+		Compact[string]("alpha"),
+		Replace(0, 1, "x", "y"),
+		Reverse[string](),
+		Delete[string](0, 1),
+		Prepend("z"),
+	}
+
+	recorded, final, err := Record(base, ops, registry())
+	require.NoError(t, err)
+	require.Equal(t, []string{"z", "b", "y", "x"}, final)
+	for i, op := range recorded {
+		require.NotZero(t, op.Checksum, "step %d", i)
+		require.Zero(t, ops[i].Checksum, "the input operations are not modified")
+	}
+
+	replayed, err := Replay(base, recorded, registry())
+	require.NoError(t, err)
+	require.Equal(t, final, replayed)
+
+	// replaying from a different base is detected at the first step
+	_, err = Replay([]string{"a", "b"}, recorded, registry())
+	require.ErrorIs(t, err, ErrChecksumMismatch)
+	require.ErrorContains(t, err, "step 0")
+
+	// as is a tampered operation
+	tampered := append([]Op[string](nil), recorded...)
+	tampered[3].Values = []string{"q"}
+	_, err = Replay(base, tampered, registry())
+	require.ErrorIs(t, err, ErrChecksumMismatch)
+	require.ErrorContains(t, err, "step 3")
+
+	_, _, err = Record(base, []Op[string]{Delete[string](0, 5)}, registry())
+	require.ErrorIs(t, err, ErrInvalidOp)
+}
+
+func TestChecksum(t *testing.T) {
+	empty, err := Checksum([]int(nil))
+	require.NoError(t, err)
+	emptyNonNil, err := Checksum([]int{})
+	require.NoError(t, err)
+	require.Equal(t, empty, emptyNonNil)
+
+	a, err := Checksum([]int{1, 2})
+	require.NoError(t, err)
+	b, err := Checksum([]int{2, 1})
+	require.NoError(t, err)
+	require.NotEqual(t, a, b)
+
+	_, err = Checksum([]func(){func() {}})
+	require.Error(t, err)
+}
+
+func TestEncoding(t *testing.T) {
+	ops, _, err := Record([]string{"a"}, []Op[string]{
+		Append("b", "c"),
+		Delete[string](0, 1),
+		Sort[string]("alpha"),
+		Reverse[string](),
+	}, registry())
+	require.NoError(t, err)
+
+	t.Run("json", func(t *testing.T) {
+		data, err := json.Marshal(ops)
+		require.NoError(t, err)
+
+		var decoded []Op[string]
+		require.NoError(t, json.Unmarshal(data, &decoded))
+		require.Equal(t, ops, decoded)
+
+		replayed, err := Replay([]string{"a"}, decoded, registry())
+		require.NoError(t, err)
+		require.Equal(t, []string{"c", "b"}, replayed)
+	})
+
+	t.Run("binary", func(t *testing.T) {
+		var buf bytes.Buffer
+		enc := NewEncoder[string](&buf)
+		for _, op := range ops {
+			require.NoError(t, enc.Encode(op))
+		}
+
+		var decoded []Op[string]
+		dec := NewDecoder[string](&buf)
+		for {
+			op, err := dec.Decode()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			require.NoError(t, err)
+			decoded = append(decoded, op)
+		}
+		require.Equal(t, ops, decoded)
+	})
+}
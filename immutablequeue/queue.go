@@ -0,0 +1,177 @@
+// Package immutablequeue provides a persistent FIFO queue. Enqueueing and dequeueing
+// return new queues in constant worst case time while sharing structure with the
+// original queue.
+package immutablequeue
+
+import (
+	"sync"
+
+	"github.com/mkeeler/go-immutable/immutablelist"
+)
+
+// Queue is a persistent first-in first-out queue implemented as Okasaki's real-time
+// queue. The front of the queue is a lazily evaluated stream and the back of the queue
+// is an immutablelist.List holding the most recently enqueued elements in reverse order.
+// Whenever the back grows larger than the front, a rotation is scheduled which moves the
+// back elements onto the end of the front one step at a time as the queue is used. This
+// guarantees that every operation runs in O(1) worst case time, even when old versions
+// of the queue are reused.
+//
+// The zero value of a Queue is an empty queue ready to use. A Queue is safe for
+// concurrent use by multiple goroutines without any additional locking.
+type Queue[E any] struct {
+	front    *stream[E]
+	frontLen int
+	rear     immutablelist.List[E]
+	// schedule points into front at the first element that has not yet been
+	// evaluated. Its length is always frontLen - rear.Len().
+	schedule *stream[E]
+}
+
+// New creates a queue by enqueueing each of the specified elements in order. The first
+// element given will be the first element dequeued.
+func New[E any](elems ...E) Queue[E] {
+	var q Queue[E]
+	for _, v := range elems {
+		q = q.Enqueue(v)
+	}
+	return q
+}
+
+// Len returns the number of elements in the queue.
+func (q Queue[E]) Len() int {
+	return q.frontLen + q.rear.Len()
+}
+
+// Enqueue returns a new queue with v added to the back of q.
+func (q Queue[E]) Enqueue(v E) Queue[E] {
+	q.rear = q.rear.Cons(v)
+	return q.exec()
+}
+
+// Peek returns the element at the front of the queue. The boolean return value will
+// be false if the queue is empty.
+func (q Queue[E]) Peek() (E, bool) {
+	c := q.front.force()
+	if c == nil {
+		var zero E
+		return zero, false
+	}
+	return c.value, true
+}
+
+// Dequeue returns the element at the front of the queue along with a new queue holding
+// the remaining elements. The boolean return value will be false if the queue is empty
+// in which case the returned queue is also empty.
+func (q Queue[E]) Dequeue() (E, Queue[E], bool) {
+	c := q.front.force()
+	if c == nil {
+		var zero E
+		return zero, q, false
+	}
+
+	q.front = c.next
+	q.frontLen -= 1
+	return c.value, q.exec(), true
+}
+
+// Range calls fn for each element of the queue in the order they would be dequeued.
+// Iteration stops early if fn returns false.
+func (q Queue[E]) Range(fn func(E) bool) {
+	for c := q.front.force(); c != nil; c = c.next.force() {
+		if !fn(c.value) {
+			return
+		}
+	}
+
+	rear := q.rear.Slice()
+	for i := len(rear) - 1; i >= 0; i-- {
+		if !fn(rear[i]) {
+			return
+		}
+	}
+}
+
+// Slice returns a newly allocated slice holding the elements of the queue in the order
+// they would be dequeued. An empty queue results in a nil slice.
+func (q Queue[E]) Slice() []E {
+	if q.Len() == 0 {
+		return nil
+	}
+
+	s := make([]E, 0, q.Len())
+	q.Range(func(v E) bool {
+		s = append(s, v)
+		return true
+	})
+	return s
+}
+
+// exec performs one step of any in progress rotation. When no rotation is in progress
+// and the rear has become one element longer than the front, a new rotation is started.
+func (q Queue[E]) exec() Queue[E] {
+	if c := q.schedule.force(); c != nil {
+		q.schedule = c.next
+		return q
+	}
+
+	q.front = rotate(q.front, q.rear, nil)
+	q.frontLen += q.rear.Len()
+	q.rear = immutablelist.List[E]{}
+	q.schedule = q.front
+	return q
+}
+
+// rotate lazily computes the stream holding the elements of front followed by the
+// elements of rear in reverse order followed by the elements of acc. The rear list
+// must be exactly one element longer than the front stream.
+func rotate[E any](front *stream[E], rear immutablelist.List[E], acc *stream[E]) *stream[E] {
+	return &stream[E]{thunk: func() *cell[E] {
+		last, _ := rear.Head()
+		c := front.force()
+		if c == nil {
+			return &cell[E]{value: last, next: acc}
+		}
+
+		next := evaluated(&cell[E]{value: last, next: acc})
+		return &cell[E]{value: c.value, next: rotate(c.next, rear.Tail(), next)}
+	}}
+}
+
+// cell is an evaluated element of a stream.
+type cell[E any] struct {
+	value E
+	next  *stream[E]
+}
+
+// stream is a lazily evaluated, memoized linked list. A nil stream is empty. The thunk
+// is evaluated at most once, after which its result is shared by all queues that
+// reference the stream.
+type stream[E any] struct {
+	once  sync.Once
+	thunk func() *cell[E]
+	cell  *cell[E]
+}
+
+// evaluated creates a stream whose first cell is already known.
+func evaluated[E any](c *cell[E]) *stream[E] {
+	s := &stream[E]{cell: c}
+	s.once.Do(func() {})
+	return s
+}
+
+// force evaluates the stream if necessary and returns its first cell. A nil cell
+// indicates that the stream is empty.
+func (s *stream[E]) force() *cell[E] {
+	if s == nil {
+		return nil
+	}
+
+	s.once.Do(func() {
+		s.cell = s.thunk()
+		// drop the reference to the thunk so that the values it captured
+		// can be garbage collected
+		s.thunk = nil
+	})
+	return s.cell
+}
@@ -0,0 +1,163 @@
+package immutablequeue
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestZeroValue(t *testing.T) {
+	var q Queue[int]
+
+	require.Equal(t, 0, q.Len())
+	require.Nil(t, q.Slice())
+
+	_, ok := q.Peek()
+	require.False(t, ok)
+
+	_, dequeued, ok := q.Dequeue()
+	require.False(t, ok)
+	require.Equal(t, 0, dequeued.Len())
+}
+
+func TestEnqueueDequeue(t *testing.T) {
+	type testCase struct {
+		elems []int
+	}
+
+	cases := map[string]testCase{
+		"single element": {
+			elems: []int{1},
+		},
+		"few elements": {
+			elems: []int{1, 2, 3},
+		},
+		"many elements": {
+			elems: func() []int {
+				var s []int
+				for i := 0; i < 100; i++ {
+					s = append(s, i)
+				}
+				return s
+			}(),
+		},
+	}
+
+	for name, tcase := range cases {
+		tcase := tcase
+
+		t.Run(name, func(t *testing.T) {
+			q := New(tcase.elems...)
+			require.Equal(t, len(tcase.elems), q.Len())
+			require.Equal(t, tcase.elems, q.Slice())
+
+			var actual []int
+			for cur := q; cur.Len() > 0; {
+				peeked, ok := cur.Peek()
+				require.True(t, ok)
+
+				var v int
+				v, cur, ok = cur.Dequeue()
+				require.True(t, ok)
+				require.Equal(t, peeked, v)
+				actual = append(actual, v)
+			}
+
+			require.Equal(t, tcase.elems, actual)
+			// dequeueing must leave the original queue untouched
+			require.Equal(t, tcase.elems, q.Slice())
+		})
+	}
+}
+
+func TestInterleaved(t *testing.T) {
+	// Compare against a simple slice model while interleaving operations and
+	// keeping every intermediate version around.
+	var (
+		q        Queue[int]
+		model    []int
+		versions []Queue[int]
+		models   [][]int
+	)
+
+	next := 0
+	for i := 0; i < 200; i++ {
+		if i%3 == 2 {
+			var v int
+			var ok bool
+			v, q, ok = q.Dequeue()
+			require.True(t, ok)
+			require.Equal(t, model[0], v)
+			model = model[1:]
+		} else {
+			q = q.Enqueue(next)
+			model = append(model, next)
+			next += 1
+		}
+
+		versions = append(versions, q)
+		models = append(models, append([]int(nil), model...))
+	}
+
+	for i, v := range versions {
+		require.Equal(t, len(models[i]), v.Len())
+		if len(models[i]) == 0 {
+			require.Nil(t, v.Slice())
+		} else {
+			require.Equal(t, models[i], v.Slice())
+		}
+	}
+}
+
+func TestBranching(t *testing.T) {
+	base := New(1, 2, 3)
+
+	a := base.Enqueue(4)
+	_, b, _ := base.Dequeue()
+	b = b.Enqueue(5)
+
+	require.Equal(t, []int{1, 2, 3}, base.Slice())
+	require.Equal(t, []int{1, 2, 3, 4}, a.Slice())
+	require.Equal(t, []int{2, 3, 5}, b.Slice())
+}
+
+func TestConcurrentReaders(t *testing.T) {
+	var elems []int
+	for i := 0; i < 1000; i++ {
+		elems = append(elems, i)
+	}
+	q := New(elems...)
+
+	var wg sync.WaitGroup
+	results := make([][]int, 8)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			var drained []int
+			for cur := q; cur.Len() > 0; {
+				var v int
+				v, cur, _ = cur.Dequeue()
+				drained = append(drained, v)
+			}
+			results[i] = drained
+		}(i)
+	}
+	wg.Wait()
+
+	for _, r := range results {
+		require.Equal(t, elems, r)
+	}
+}
+
+func TestRange(t *testing.T) {
+	q := New(1, 2, 3, 4, 5)
+
+	var firstThree []int
+	q.Range(func(v int) bool {
+		firstThree = append(firstThree, v)
+		return len(firstThree) < 3
+	})
+	require.Equal(t, []int{1, 2, 3}, firstThree)
+}
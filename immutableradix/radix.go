@@ -0,0 +1,397 @@
+// Package immutableradix provides a persistent radix tree keyed by strings. Every update
+// returns a new tree which shares all unmodified nodes with the tree it was derived from,
+// allowing readers to keep using a snapshot of the tree while it is being updated.
+package immutableradix
+
+import (
+	"sort"
+	"strings"
+)
+
+// owner identifies the transaction that created a node. Nodes owned by the active
+// transaction are not yet visible through any tree and so may be modified in place.
+type owner struct {
+	_ byte
+}
+
+type leaf[V any] struct {
+	key   string
+	value V
+}
+
+type edge[V any] struct {
+	label byte
+	node  *node[V]
+}
+
+// node is a single node of the tree. The prefix holds the portion of the key consumed
+// by the edge leading into the node and edges are kept sorted by their label so that
+// walking the tree visits keys in lexicographic order.
+type node[V any] struct {
+	prefix string
+	leaf   *leaf[V]
+	edges  []edge[V]
+	owner  *owner
+}
+
+func (n *node[V]) getEdge(label byte) (int, *node[V]) {
+	idx := sort.Search(len(n.edges), func(i int) bool {
+		return n.edges[i].label >= label
+	})
+	if idx < len(n.edges) && n.edges[idx].label == label {
+		return idx, n.edges[idx].node
+	}
+	return idx, nil
+}
+
+func (n *node[V]) isEmpty() bool {
+	return n.leaf == nil && len(n.edges) == 0
+}
+
+// Tree is a persistent radix tree mapping string keys to values of type V. Updates copy
+// only the nodes along the path to the modified key. Multiple updates can be batched
+// with a Txn which avoids copying the same nodes more than once.
+//
+// The zero value of a Tree is an empty tree ready to use. A Tree is safe for concurrent
+// use by multiple goroutines as none of its methods modify it.
+type Tree[V any] struct {
+	root *node[V]
+	size int
+}
+
+// Len returns the number of keys stored in the tree.
+func (t Tree[V]) Len() int {
+	return t.size
+}
+
+// Get returns the value stored for the key along with whether the key was found.
+func (t Tree[V]) Get(key string) (V, bool) {
+	n := t.root
+	search := key
+	for n != nil {
+		if len(search) == 0 {
+			if n.leaf != nil {
+				return n.leaf.value, true
+			}
+			break
+		}
+
+		_, n = n.getEdge(search[0])
+		if n == nil || !strings.HasPrefix(search, n.prefix) {
+			break
+		}
+		search = search[len(n.prefix):]
+	}
+
+	var zero V
+	return zero, false
+}
+
+// Insert returns a new tree with the key set to the value v.
+func (t Tree[V]) Insert(key string, v V) Tree[V] {
+	txn := t.Txn()
+	txn.Insert(key, v)
+	return txn.Commit()
+}
+
+// Delete returns a new tree without the key. If the key is not present, the returned
+// tree is identical to t.
+func (t Tree[V]) Delete(key string) Tree[V] {
+	txn := t.Txn()
+	txn.Delete(key)
+	return txn.Commit()
+}
+
+// LongestPrefix finds the longest key in the tree that is a prefix of s. It returns the
+// key, its value and whether any such key was found.
+func (t Tree[V]) LongestPrefix(s string) (string, V, bool) {
+	var last *leaf[V]
+	n := t.root
+	search := s
+	for n != nil {
+		if n.leaf != nil {
+			last = n.leaf
+		}
+		if len(search) == 0 {
+			break
+		}
+
+		_, n = n.getEdge(search[0])
+		if n == nil || !strings.HasPrefix(search, n.prefix) {
+			break
+		}
+		search = search[len(n.prefix):]
+	}
+
+	if last == nil {
+		var zero V
+		return "", zero, false
+	}
+	return last.key, last.value, true
+}
+
+// Walk calls fn for every key and value in the tree in lexicographic key order.
+// Iteration stops early if fn returns false.
+func (t Tree[V]) Walk(fn func(key string, v V) bool) {
+	if t.root != nil {
+		walk(t.root, fn)
+	}
+}
+
+// WalkPrefix calls fn for every key starting with prefix, along with its value, in
+// lexicographic key order. Iteration stops early if fn returns false.
+func (t Tree[V]) WalkPrefix(prefix string, fn func(key string, v V) bool) {
+	n := t.root
+	search := prefix
+	for n != nil {
+		if len(search) == 0 {
+			walk(n, fn)
+			return
+		}
+
+		_, n = n.getEdge(search[0])
+		if n == nil {
+			return
+		}
+
+		switch {
+		case strings.HasPrefix(search, n.prefix):
+			search = search[len(n.prefix):]
+		case strings.HasPrefix(n.prefix, search):
+			// the remaining search ends part way through this node's prefix so
+			// every key below it starts with the requested prefix
+			walk(n, fn)
+			return
+		default:
+			return
+		}
+	}
+}
+
+// Txn starts a new transaction based on the current state of the tree. The tree itself
+// is not affected by any updates performed within the transaction.
+func (t Tree[V]) Txn() *Txn[V] {
+	return &Txn[V]{root: t.root, size: t.size, owner: &owner{}}
+}
+
+func walk[V any](n *node[V], fn func(string, V) bool) bool {
+	if n.leaf != nil && !fn(n.leaf.key, n.leaf.value) {
+		return false
+	}
+	for _, e := range n.edges {
+		if !walk(e.node, fn) {
+			return false
+		}
+	}
+	return true
+}
+
+// Txn batches multiple updates to a tree into a single new version. Nodes created by a
+// transaction are modified in place by subsequent updates within the same transaction,
+// so batching many updates performs less copying than applying them to a Tree one at a
+// time. A Txn is not safe for concurrent use by multiple goroutines.
+type Txn[V any] struct {
+	root  *node[V]
+	size  int
+	owner *owner
+}
+
+// Len returns the number of keys stored in the transaction's current view of the tree.
+func (t *Txn[V]) Len() int {
+	return t.size
+}
+
+// Get returns the value stored for the key, taking into account all updates performed
+// within the transaction so far.
+func (t *Txn[V]) Get(key string) (V, bool) {
+	return Tree[V]{root: t.root}.Get(key)
+}
+
+// Insert sets the key to the value v. It returns the previous value of the key along
+// with whether the key already existed.
+func (t *Txn[V]) Insert(key string, v V) (V, bool) {
+	root := t.root
+	if root == nil {
+		root = &node[V]{owner: t.owner}
+	}
+
+	newRoot, old, updated := t.insert(root, key, key, v)
+	t.root = newRoot
+	if !updated {
+		t.size += 1
+	}
+	return old, updated
+}
+
+// Delete removes the key. It returns the value the key had along with whether the key
+// existed at all.
+func (t *Txn[V]) Delete(key string) (V, bool) {
+	var zero V
+	if t.root == nil {
+		return zero, false
+	}
+
+	newRoot, old := t.delete(t.root, key)
+	if old == nil {
+		return zero, false
+	}
+
+	t.root = newRoot
+	t.size -= 1
+	return old.value, true
+}
+
+// Commit returns a tree holding the result of all updates performed within the
+// transaction. The transaction may continue to be used afterwards. Further updates
+// will not affect the returned tree.
+func (t *Txn[V]) Commit() Tree[V] {
+	// Hand ownership of all nodes created so far over to the returned tree so
+	// that subsequent updates copy them instead of modifying them in place.
+	t.owner = &owner{}
+	return Tree[V]{root: t.root, size: t.size}
+}
+
+// writable returns n itself if it was created by this transaction and otherwise a copy
+// of n owned by this transaction.
+func (t *Txn[V]) writable(n *node[V]) *node[V] {
+	if n.owner == t.owner {
+		return n
+	}
+
+	nc := &node[V]{
+		prefix: n.prefix,
+		leaf:   n.leaf,
+		owner:  t.owner,
+	}
+	if len(n.edges) > 0 {
+		nc.edges = make([]edge[V], len(n.edges))
+		copy(nc.edges, n.edges)
+	}
+	return nc
+}
+
+// addEdge inserts e into the edges of the writable node n keeping them sorted.
+func addEdge[V any](n *node[V], e edge[V]) {
+	idx, _ := n.getEdge(e.label)
+	n.edges = append(n.edges, edge[V]{})
+	copy(n.edges[idx+1:], n.edges[idx:])
+	n.edges[idx] = e
+}
+
+// insert sets the key within the subtree rooted at n, where search is the remainder of
+// the key after the prefixes of n and all its ancestors.
+func (t *Txn[V]) insert(n *node[V], search, key string, v V) (*node[V], V, bool) {
+	var zero V
+
+	if len(search) == 0 {
+		nc := t.writable(n)
+		old, updated := zero, false
+		if nc.leaf != nil {
+			old, updated = nc.leaf.value, true
+		}
+		nc.leaf = &leaf[V]{key: key, value: v}
+		return nc, old, updated
+	}
+
+	idx, child := n.getEdge(search[0])
+	if child == nil {
+		nc := t.writable(n)
+		addEdge(nc, edge[V]{label: search[0], node: &node[V]{
+			prefix: search,
+			leaf:   &leaf[V]{key: key, value: v},
+			owner:  t.owner,
+		}})
+		return nc, zero, false
+	}
+
+	common := commonPrefixLen(search, child.prefix)
+	if common == len(child.prefix) {
+		newChild, old, updated := t.insert(child, search[common:], key, v)
+		if newChild == child {
+			// the child was already owned by this transaction and was
+			// modified in place
+			return n, old, updated
+		}
+
+		nc := t.writable(n)
+		nc.edges[idx].node = newChild
+		return nc, old, updated
+	}
+
+	// The key diverges part way through the child's prefix so a new node must
+	// be introduced where the two paths split.
+	split := &node[V]{prefix: search[:common], owner: t.owner}
+	movedChild := t.writable(child)
+	movedChild.prefix = child.prefix[common:]
+	addEdge(split, edge[V]{label: movedChild.prefix[0], node: movedChild})
+
+	rest := search[common:]
+	newLeaf := &leaf[V]{key: key, value: v}
+	if len(rest) == 0 {
+		split.leaf = newLeaf
+	} else {
+		addEdge(split, edge[V]{label: rest[0], node: &node[V]{
+			prefix: rest,
+			leaf:   newLeaf,
+			owner:  t.owner,
+		}})
+	}
+
+	nc := t.writable(n)
+	nc.edges[idx].node = split
+	return nc, zero, false
+}
+
+// delete removes the key within the subtree rooted at n. The returned leaf is nil when
+// the key was not found in which case the returned node should be ignored.
+func (t *Txn[V]) delete(n *node[V], search string) (*node[V], *leaf[V]) {
+	if len(search) == 0 {
+		if n.leaf == nil {
+			return nil, nil
+		}
+
+		old := n.leaf
+		nc := t.writable(n)
+		nc.leaf = nil
+		return nc, old
+	}
+
+	idx, child := n.getEdge(search[0])
+	if child == nil || !strings.HasPrefix(search, child.prefix) {
+		return nil, nil
+	}
+
+	newChild, old := t.delete(child, search[len(child.prefix):])
+	if old == nil {
+		return nil, nil
+	}
+
+	nc := t.writable(n)
+	switch {
+	case newChild.isEmpty():
+		// remove the edge to the now empty child
+		nc.edges = append(nc.edges[:idx], nc.edges[idx+1:]...)
+		if len(nc.edges) == 0 {
+			nc.edges = nil
+		}
+	case newChild.leaf == nil && len(newChild.edges) == 1:
+		// the child only serves to connect to a single grandchild so the two
+		// can be merged into one node
+		merged := t.writable(newChild.edges[0].node)
+		merged.prefix = newChild.prefix + merged.prefix
+		nc.edges[idx].node = merged
+	default:
+		nc.edges[idx].node = newChild
+	}
+	return nc, old
+}
+
+func commonPrefixLen(a, b string) int {
+	n := min(len(a), len(b))
+	for i := 0; i < n; i++ {
+		if a[i] != b[i] {
+			return i
+		}
+	}
+	return n
+}
@@ -0,0 +1,321 @@
+package immutableradix
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// keys returns all keys of the tree in the order they are walked.
+func keys[V any](t Tree[V]) []string {
+	var out []string
+	t.Walk(func(k string, _ V) bool {
+		out = append(out, k)
+		return true
+	})
+	return out
+}
+
+// requireCompact verifies that every non-root node either holds a value or branches.
+func requireCompact[V any](t *testing.T, n *node[V], isRoot bool) {
+	t.Helper()
+
+	if n == nil {
+		return
+	}
+	if !isRoot {
+		require.False(t, n.leaf == nil && len(n.edges) < 2, "node %q should have been merged or removed", n.prefix)
+	}
+	for _, e := range n.edges {
+		require.Equal(t, e.label, e.node.prefix[0])
+		requireCompact(t, e.node, false)
+	}
+}
+
+func TestInsertAndGet(t *testing.T) {
+	var tree Tree[int]
+
+	inputs := []string{"foo", "foobar", "foobaz", "fo", "bar", "", "f"}
+	for i, k := range inputs {
+		tree = tree.Insert(k, i)
+	}
+
+	require.Equal(t, len(inputs), tree.Len())
+	for i, k := range inputs {
+		v, ok := tree.Get(k)
+		require.True(t, ok, "key %q", k)
+		require.Equal(t, i, v)
+	}
+
+	for _, k := range []string{"fooba", "b", "foobarbaz", "x"} {
+		_, ok := tree.Get(k)
+		require.False(t, ok, "key %q", k)
+	}
+
+	// updating an existing key does not change the size
+	updated := tree.Insert("foo", 42)
+	require.Equal(t, tree.Len(), updated.Len())
+	v, _ := updated.Get("foo")
+	require.Equal(t, 42, v)
+	v, _ = tree.Get("foo")
+	require.Equal(t, 0, v)
+
+	requireCompact(t, tree.root, true)
+}
+
+func TestDelete(t *testing.T) {
+	base := Tree[int]{}.
+		Insert("foo", 1).
+		Insert("foobar", 2).
+		Insert("foobaz", 3).
+		Insert("fizz", 4)
+
+	type testCase struct {
+		key      string
+		expected []string
+	}
+
+	cases := map[string]testCase{
+		"missing key": {
+			key:      "fooba",
+			expected: []string{"fizz", "foo", "foobar", "foobaz"},
+		},
+		"leaf key": {
+			key:      "foobar",
+			expected: []string{"fizz", "foo", "foobaz"},
+		},
+		"inner key": {
+			key:      "foo",
+			expected: []string{"fizz", "foobar", "foobaz"},
+		},
+		"branch key": {
+			key:      "fizz",
+			expected: []string{"foo", "foobar", "foobaz"},
+		},
+	}
+
+	for name, tcase := range cases {
+		tcase := tcase
+
+		t.Run(name, func(t *testing.T) {
+			actual := base.Delete(tcase.key)
+			require.Equal(t, tcase.expected, keys(actual))
+			require.Equal(t, len(tcase.expected), actual.Len())
+			requireCompact(t, actual.root, true)
+
+			// the original tree is unaffected
+			require.Equal(t, []string{"fizz", "foo", "foobar", "foobaz"}, keys(base))
+		})
+	}
+}
+
+func TestLongestPrefix(t *testing.T) {
+	tree := Tree[string]{}.
+		Insert("/", "root").
+		Insert("/api", "api").
+		Insert("/api/v1", "v1").
+		Insert("/static", "static")
+
+	type testCase struct {
+		input    string
+		expected string
+		found    bool
+	}
+
+	cases := map[string]testCase{
+		"exact match": {
+			input:    "/api/v1",
+			expected: "/api/v1",
+			found:    true,
+		},
+		"longer input": {
+			input:    "/api/v1/users",
+			expected: "/api/v1",
+			found:    true,
+		},
+		"partial edge": {
+			input:    "/api/v2",
+			expected: "/api",
+			found:    true,
+		},
+		"root fallback": {
+			input:    "/other",
+			expected: "/",
+			found:    true,
+		},
+		"no match": {
+			input: "other",
+		},
+	}
+
+	for name, tcase := range cases {
+		tcase := tcase
+
+		t.Run(name, func(t *testing.T) {
+			k, _, ok := tree.LongestPrefix(tcase.input)
+			require.Equal(t, tcase.found, ok)
+			require.Equal(t, tcase.expected, k)
+		})
+	}
+}
+
+func TestWalkPrefix(t *testing.T) {
+	tree := Tree[int]{}.
+		Insert("foobar", 1).
+		Insert("foobaz", 2).
+		Insert("foo", 3).
+		Insert("fizz", 4).
+		Insert("bar", 5)
+
+	type testCase struct {
+		prefix   string
+		expected []string
+	}
+
+	cases := map[string]testCase{
+		"empty prefix": {
+			prefix:   "",
+			expected: []string{"bar", "fizz", "foo", "foobar", "foobaz"},
+		},
+		"shared prefix": {
+			prefix:   "f",
+			expected: []string{"fizz", "foo", "foobar", "foobaz"},
+		},
+		"exact node": {
+			prefix:   "foo",
+			expected: []string{"foo", "foobar", "foobaz"},
+		},
+		"part way through a node": {
+			prefix:   "fooba",
+			expected: []string{"foobar", "foobaz"},
+		},
+		"no matches": {
+			prefix:   "fooq",
+			expected: nil,
+		},
+	}
+
+	for name, tcase := range cases {
+		tcase := tcase
+
+		t.Run(name, func(t *testing.T) {
+			var actual []string
+			tree.WalkPrefix(tcase.prefix, func(k string, _ int) bool {
+				actual = append(actual, k)
+				return true
+			})
+			require.Equal(t, tcase.expected, actual)
+		})
+	}
+
+	var first []string
+	tree.WalkPrefix("f", func(k string, _ int) bool {
+		first = append(first, k)
+		return false
+	})
+	require.Equal(t, []string{"fizz"}, first)
+}
+
+func TestTxn(t *testing.T) {
+	base := Tree[int]{}.Insert("a", 1).Insert("b", 2)
+
+	txn := base.Txn()
+	txn.Insert("c", 3)
+	old, updated := txn.Insert("a", 10)
+	require.True(t, updated)
+	require.Equal(t, 1, old)
+
+	old, deleted := txn.Delete("b")
+	require.True(t, deleted)
+	require.Equal(t, 2, old)
+
+	_, deleted = txn.Delete("missing")
+	require.False(t, deleted)
+
+	v, ok := txn.Get("c")
+	require.True(t, ok)
+	require.Equal(t, 3, v)
+	require.Equal(t, 2, txn.Len())
+
+	committed := txn.Commit()
+	require.Equal(t, []string{"a", "c"}, keys(committed))
+
+	// further updates on the transaction do not leak into the committed tree
+	txn.Insert("d", 4)
+	txn.Insert("c", 30)
+	v, _ = committed.Get("c")
+	require.Equal(t, 3, v)
+	require.Equal(t, []string{"a", "c"}, keys(committed))
+	require.Equal(t, []string{"a", "c", "d"}, keys(txn.Commit()))
+
+	// the base tree never changes
+	require.Equal(t, []string{"a", "b"}, keys(base))
+	v, _ = base.Get("a")
+	require.Equal(t, 1, v)
+}
+
+func TestRandomized(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	alphabet := "abc/"
+
+	randomKey := func() string {
+		b := make([]byte, r.Intn(6))
+		for i := range b {
+			b[i] = alphabet[r.Intn(len(alphabet))]
+		}
+		return string(b)
+	}
+
+	var tree Tree[int]
+	model := map[string]int{}
+
+	type version struct {
+		tree  Tree[int]
+		model map[string]int
+	}
+	var versions []version
+
+	for i := 0; i < 2000; i++ {
+		k := randomKey()
+		if r.Intn(3) == 0 {
+			tree = tree.Delete(k)
+			delete(model, k)
+		} else {
+			tree = tree.Insert(k, i)
+			model[k] = i
+		}
+
+		if i%100 == 0 {
+			snapshot := make(map[string]int, len(model))
+			for k, v := range model {
+				snapshot[k] = v
+			}
+			versions = append(versions, version{tree: tree, model: snapshot})
+		}
+	}
+	versions = append(versions, version{tree: tree, model: model})
+
+	for i, v := range versions {
+		require.Equal(t, len(v.model), v.tree.Len(), "version %d", i)
+		requireCompact(t, v.tree.root, true)
+
+		expected := make([]string, 0, len(v.model))
+		for k := range v.model {
+			expected = append(expected, k)
+		}
+		sort.Strings(expected)
+		if len(expected) == 0 {
+			expected = nil
+		}
+		require.Equal(t, expected, keys(v.tree), "version %d", i)
+
+		for k, val := range v.model {
+			actual, ok := v.tree.Get(k)
+			require.True(t, ok, fmt.Sprintf("version %d key %q", i, k))
+			require.Equal(t, val, actual)
+		}
+	}
+}
@@ -0,0 +1,255 @@
+// Package immutablerga provides a replicated growable array, a sequence CRDT which lets
+// multiple replicas edit the same list independently and converge to the same list once
+// they have exchanged their operations, regardless of the order in which the operations
+// are delivered.
+package immutablerga
+
+import (
+	"cmp"
+	"fmt"
+
+	"github.com/mkeeler/go-immutable/immutableslice"
+)
+
+// ID uniquely identifies an element of the list. IDs are Lamport timestamps: the
+// counter is larger than the counter of every element the replica knew about when it
+// inserted the element, and the replica name breaks ties between replicas.
+type ID struct {
+	Counter uint64 `json:"counter"`
+	Replica string `json:"replica"`
+}
+
+// IsZero returns whether id is the zero ID, which denotes the start of the list.
+func (id ID) IsZero() bool {
+	return id == ID{}
+}
+
+// Compare returns -1, 0 or +1 depending on whether id orders before, equal to or after
+// other.
+func (id ID) Compare(other ID) int {
+	if c := cmp.Compare(id.Counter, other.Counter); c != 0 {
+		return c
+	}
+	return cmp.Compare(id.Replica, other.Replica)
+}
+
+// String returns the ID in the form counter@replica.
+func (id ID) String() string {
+	return fmt.Sprintf("%d@%s", id.Counter, id.Replica)
+}
+
+// Op is an operation to be exchanged between replicas. Insert operations add the
+// element with the ID and Value right after the element After, or at the start of the
+// list when After is the zero ID. Delete operations remove the element with the ID.
+type Op[E any] struct {
+	ID     ID   `json:"id"`
+	After  ID   `json:"after"`
+	Value  E    `json:"value"`
+	Delete bool `json:"delete,omitempty"`
+}
+
+// node is an element of the list. Deleted elements are kept as tombstones so that
+// operations referring to them can still be integrated.
+type node[E any] struct {
+	id      ID
+	value   E
+	deleted bool
+}
+
+// RGA is the state of a replica of a list. Every method returns a new state and never
+// modifies the state it was called on, so old states remain valid and may be shared
+// freely.
+//
+// States must be created with New. An RGA is safe for concurrent use by multiple
+// goroutines as none of its methods modify it. Locating an element takes O(n) time where
+// n is the number of elements ever inserted, including deleted ones.
+type RGA[E any] struct {
+	replica string
+	clock   uint64
+	nodes   []node[E]
+	size    int
+	// pending holds the operations which were received before the operations they
+	// depend on.
+	pending []Op[E]
+}
+
+// New creates an empty list for the replica with the specified name. Every replica
+// editing the list must use a different name.
+func New[E any](replica string) RGA[E] {
+	return RGA[E]{replica: replica}
+}
+
+// Replica returns the name of the replica.
+func (r RGA[E]) Replica() string {
+	return r.replica
+}
+
+// Len returns the number of elements in the list.
+func (r RGA[E]) Len() int {
+	return r.size
+}
+
+// Pending returns the number of received operations which could not be applied yet
+// because they depend on operations which were not received yet.
+func (r RGA[E]) Pending() int {
+	return len(r.pending)
+}
+
+// Slice returns a newly allocated slice holding the elements of the list. An empty list
+// results in a nil slice.
+func (r RGA[E]) Slice() []E {
+	if r.size == 0 {
+		return nil
+	}
+
+	s := make([]E, 0, r.size)
+	for _, n := range r.nodes {
+		if !n.deleted {
+			s = append(s, n.value)
+		}
+	}
+	return s
+}
+
+// Insert inserts the values v at index i of the list, like immutableslice.Insert, and
+// returns the new state along with the operations to send to the other replicas. It
+// panics if i is out of range.
+func (r RGA[E]) Insert(i int, v ...E) (RGA[E], []Op[E]) {
+	if i < 0 || i > r.size {
+		panic(fmt.Sprintf("immutablerga: index %d out of range [0:%d]", i, r.size))
+	}
+
+	var after ID
+	if i > 0 {
+		after = r.nodes[r.position(i-1)].id
+	}
+
+	ops := make([]Op[E], 0, len(v))
+	for _, value := range v {
+		op := Op[E]{ID: ID{Counter: r.clock + 1, Replica: r.replica}, After: after, Value: value}
+		r = r.integrate(op)
+		ops = append(ops, op)
+		after = op.ID
+	}
+	return r, ops
+}
+
+// Delete removes the elements at indexes i up to but excluding j, like
+// immutableslice.Delete, and returns the new state along with the operations to send to
+// the other replicas. It panics if i and j are not a valid range of the list.
+func (r RGA[E]) Delete(i, j int) (RGA[E], []Op[E]) {
+	if i < 0 || i > j || j > r.size {
+		panic(fmt.Sprintf("immutablerga: range [%d:%d] out of range [0:%d]", i, j, r.size))
+	}
+
+	ops := make([]Op[E], 0, j-i)
+	for k := i; k < j; k++ {
+		// each deletion shifts the remaining elements, so the next one is always at i
+		op := Op[E]{ID: r.nodes[r.position(i)].id, Delete: true}
+		r = r.integrate(op)
+		ops = append(ops, op)
+	}
+	return r, ops
+}
+
+// Apply integrates operations received from other replicas and returns the new state.
+// Operations may be delivered in any order and more than once. Operations depending on
+// an element which is not known yet are kept until it is received.
+func (r RGA[E]) Apply(ops ...Op[E]) RGA[E] {
+	for _, op := range ops {
+		if !r.ready(op) {
+			r.pending = immutableslice.Append(r.pending, op)
+			continue
+		}
+		r = r.integrate(op)
+		r = r.applyPending()
+	}
+	return r
+}
+
+// applyPending integrates the pending operations which became ready until no further
+// progress is made.
+func (r RGA[E]) applyPending() RGA[E] {
+	for progress := true; progress; {
+		progress = false
+		for i, op := range r.pending {
+			if r.ready(op) {
+				r.pending = immutableslice.Delete(r.pending, i, i+1)
+				r = r.integrate(op)
+				progress = true
+				break
+			}
+		}
+	}
+	return r
+}
+
+// ready returns whether every element op depends on is known.
+func (r RGA[E]) ready(op Op[E]) bool {
+	if op.Delete {
+		return r.index(op.ID) >= 0
+	}
+	return op.After.IsZero() || r.index(op.After) >= 0
+}
+
+// integrate applies a ready operation. Duplicate operations leave the state unchanged.
+func (r RGA[E]) integrate(op Op[E]) RGA[E] {
+	r.clock = max(r.clock, op.ID.Counter)
+
+	if op.Delete {
+		idx := r.index(op.ID)
+		if r.nodes[idx].deleted {
+			return r
+		}
+		nodes := immutableslice.Concat(r.nodes)
+		nodes[idx].deleted = true
+		r.nodes = nodes
+		r.size -= 1
+		return r
+	}
+
+	if r.index(op.ID) >= 0 {
+		return r
+	}
+
+	// Elements inserted concurrently after the same element are ordered by descending
+	// ID. Every element following the insertion point with a larger ID, including
+	// the elements inserted after it which necessarily have larger IDs too, stays in
+	// front of the new element.
+	pos := 0
+	if !op.After.IsZero() {
+		pos = r.index(op.After) + 1
+	}
+	for pos < len(r.nodes) && r.nodes[pos].id.Compare(op.ID) > 0 {
+		pos += 1
+	}
+
+	r.nodes = immutableslice.Insert(r.nodes, pos, node[E]{id: op.ID, value: op.Value})
+	r.size += 1
+	return r
+}
+
+// index returns the position of the element with the specified ID within the nodes or
+// -1 if it is not known.
+func (r RGA[E]) index(id ID) int {
+	for i, n := range r.nodes {
+		if n.id == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// position returns the position within the nodes of the visible element at index i.
+func (r RGA[E]) position(i int) int {
+	for pos, n := range r.nodes {
+		if n.deleted {
+			continue
+		}
+		if i == 0 {
+			return pos
+		}
+		i -= 1
+	}
+	panic("immutablerga: index out of range")
+}
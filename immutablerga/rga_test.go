@@ -0,0 +1,200 @@
+package immutablerga
+
+import (
+	"encoding/json"
+	"math/rand"
+	"testing"
+
+	"github.com/mkeeler/go-immutable/immutableslice"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalEdits(t *testing.T) {
+	r := New[string]("a")
+	require.Equal(t, "a", r.Replica())
+	require.Nil(t, r.Slice())
+
+	type testCase struct {
+		edit func(r RGA[string]) (RGA[string], []Op[string])
+		ref  func(s []string) []string
+		ops  int
+	}
+
+	cases := map[string]testCase{
+		"insert at start": {
+			edit: func(r RGA[string]) (RGA[string], []Op[string]) { return r.Insert(0, "x", "y") },
+			ref:  func(s []string) []string { return immutableslice.Insert(s, 0, "x", "y") },
+			ops:  2,
+		},
+		"insert in middle": {
+			edit: func(r RGA[string]) (RGA[string], []Op[string]) { return r.Insert(2, "x") },
+			ref:  func(s []string) []string { return immutableslice.Insert(s, 2, "x") },
+			ops:  1,
+		},
+		"insert at end": {
+			edit: func(r RGA[string]) (RGA[string], []Op[string]) { return r.Insert(4, "x", "y") },
+			ref:  func(s []string) []string { return immutableslice.Insert(s, 4, "x", "y") },
+			ops:  2,
+		},
+		"delete range": {
+			edit: func(r RGA[string]) (RGA[string], []Op[string]) { return r.Delete(1, 3) },
+			ref:  func(s []string) []string { return immutableslice.Delete(s, 1, 3) },
+			ops:  2,
+		},
+		"delete nothing": {
+			edit: func(r RGA[string]) (RGA[string], []Op[string]) { return r.Delete(2, 2) },
+			ref:  func(s []string) []string { return s },
+			ops:  0,
+		},
+	}
+
+	for name, tcase := range cases {
+		tcase := tcase
+
+		t.Run(name, func(t *testing.T) {
+			base, insertOps := New[string]("a").Insert(0, "a", "b", "c", "d")
+			base, deleteOps := base.Delete(1, 2)
+			base, reinsertOps := base.Insert(1, "b")
+
+			actual, ops := tcase.edit(base)
+			require.Equal(t, tcase.ref(base.Slice()), actual.Slice())
+			require.Equal(t, len(actual.Slice()), actual.Len())
+			require.Len(t, ops, tcase.ops)
+
+			// the original state is unaffected
+			require.Equal(t, []string{"a", "b", "c", "d"}, base.Slice())
+
+			// applying the operations to another replica produces the same list
+			synced := New[string]("b").
+				Apply(insertOps...).
+				Apply(deleteOps...).
+				Apply(reinsertOps...).
+				Apply(ops...)
+			require.Equal(t, actual.Slice(), synced.Slice())
+		})
+	}
+
+	require.Panics(t, func() { r.Insert(1, "x") })
+	require.Panics(t, func() { r.Delete(0, 1) })
+}
+
+func TestConcurrentInserts(t *testing.T) {
+	base, baseOps := New[string]("a").Insert(0, "start", "end")
+	b := New[string]("b").Apply(baseOps...)
+
+	// both replicas insert at the same position without knowing of each other
+	a, aOps := base.Insert(1, "a1", "a2")
+	b, bOps := b.Insert(1, "b1", "b2")
+
+	a = a.Apply(bOps...)
+	b = b.Apply(aOps...)
+	require.Equal(t, a.Slice(), b.Slice())
+	require.Equal(t, "start", a.Slice()[0])
+	require.Equal(t, "end", a.Slice()[5])
+
+	// the runs of each replica are not interleaved
+	require.Contains(t, [][]string{
+		{"start", "a1", "a2", "b1", "b2", "end"},
+		{"start", "b1", "b2", "a1", "a2", "end"},
+	}, a.Slice())
+}
+
+func TestConcurrentDeleteAndInsert(t *testing.T) {
+	base, baseOps := New[int]("a").Insert(0, 1, 2, 3)
+	b := New[int]("b").Apply(baseOps...)
+
+	// one replica deletes an element while the other inserts after it
+	a, aOps := base.Delete(1, 2)
+	b, bOps := b.Insert(2, 10)
+
+	a = a.Apply(bOps...)
+	b = b.Apply(aOps...)
+	require.Equal(t, []int{1, 10, 3}, a.Slice())
+	require.Equal(t, a.Slice(), b.Slice())
+}
+
+func TestOutOfOrderDelivery(t *testing.T) {
+	_, insertOps := New[string]("a").Insert(0, "x", "y", "z")
+	a := New[string]("a").Apply(insertOps...)
+	_, deleteOps := a.Delete(0, 1)
+
+	// the operations arrive in reverse and duplicated
+	r := New[string]("b")
+	r = r.Apply(deleteOps...)
+	require.Equal(t, 1, r.Pending())
+	r = r.Apply(insertOps[2], insertOps[1])
+	require.Equal(t, 3, r.Pending())
+	require.Nil(t, r.Slice())
+
+	r = r.Apply(insertOps...)
+	require.Zero(t, r.Pending())
+	require.Equal(t, []string{"y", "z"}, r.Slice())
+
+	r = r.Apply(deleteOps...).Apply(insertOps...)
+	require.Equal(t, []string{"y", "z"}, r.Slice())
+	require.Equal(t, 2, r.Len())
+}
+
+func TestConvergence(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+
+	replicas := []RGA[int]{New[int]("a"), New[int]("b"), New[int]("c")}
+	var all []Op[int]
+
+	// each round, every replica makes some local edits and then receives a random
+	// subset of all operations so far in a random order
+	for round := 0; round < 30; round++ {
+		for i := range replicas {
+			var ops []Op[int]
+			if replicas[i].Len() > 0 && rng.Intn(3) == 0 {
+				start := rng.Intn(replicas[i].Len())
+				end := start + rng.Intn(replicas[i].Len()-start+1)
+				replicas[i], ops = replicas[i].Delete(start, end)
+			} else {
+				pos := rng.Intn(replicas[i].Len() + 1)
+				replicas[i], ops = replicas[i].Insert(pos, round*10+i, round*10+i)
+			}
+			all = append(all, ops...)
+		}
+
+		for i := range replicas {
+			perm := rng.Perm(len(all))
+			for _, p := range perm[:rng.Intn(len(all)+1)] {
+				replicas[i] = replicas[i].Apply(all[p])
+			}
+		}
+	}
+
+	// finally every replica receives every operation in a different order
+	for i := range replicas {
+		for _, p := range rng.Perm(len(all)) {
+			replicas[i] = replicas[i].Apply(all[p])
+		}
+		require.Zero(t, replicas[i].Pending())
+	}
+
+	require.NotEmpty(t, replicas[0].Slice())
+	require.Equal(t, replicas[0].Slice(), replicas[1].Slice())
+	require.Equal(t, replicas[0].Slice(), replicas[2].Slice())
+}
+
+func TestOpEncoding(t *testing.T) {
+	r, ops := New[string]("a").Insert(0, "x", "y")
+	_, deleteOps := r.Delete(0, 1)
+	ops = append(ops, deleteOps...)
+
+	data, err := json.Marshal(ops)
+	require.NoError(t, err)
+
+	var decoded []Op[string]
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.Equal(t, ops, decoded)
+	require.Equal(t, []string{"y"}, New[string]("b").Apply(decoded...).Slice())
+}
+
+func TestID(t *testing.T) {
+	require.True(t, ID{}.IsZero())
+	require.Equal(t, -1, ID{1, "b"}.Compare(ID{2, "a"}))
+	require.Equal(t, 1, ID{1, "b"}.Compare(ID{1, "a"}))
+	require.Equal(t, "3@a", ID{3, "a"}.String())
+}
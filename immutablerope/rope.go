@@ -0,0 +1,483 @@
+// Package immutablerope provides a persistent rope for efficiently editing large texts.
+// Edits return new ropes that share all unmodified text with the rope they were derived
+// from which makes it cheap to keep many versions of a document around, for example to
+// implement undo history.
+package immutablerope
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// maxLeafLen is the maximum number of bytes stored within a single leaf when building
+// ropes. Small leaves resulting from edits are merged as long as they fit within it.
+const maxLeafLen = 512
+
+// node is either a leaf holding a chunk of text or an inner node joining two non-empty
+// subtrees. Every node caches the metrics of the text it represents so that offsets can
+// be located in time proportional to the height of the tree.
+type node struct {
+	text   string
+	left   *node
+	right  *node
+	height int
+	bytes  int
+	runes  int
+	lines  int
+}
+
+func (n *node) isLeaf() bool {
+	return n.left == nil
+}
+
+func (n *node) getHeight() int {
+	if n == nil {
+		return 0
+	}
+	return n.height
+}
+
+func newLeaf(text string) *node {
+	if text == "" {
+		return nil
+	}
+
+	return &node{
+		text:   text,
+		height: 1,
+		bytes:  len(text),
+		runes:  utf8.RuneCountInString(text),
+		lines:  strings.Count(text, "\n"),
+	}
+}
+
+func newInner(left, right *node) *node {
+	return &node{
+		left:   left,
+		right:  right,
+		height: max(left.height, right.height) + 1,
+		bytes:  left.bytes + right.bytes,
+		runes:  left.runes + right.runes,
+		lines:  left.lines + right.lines,
+	}
+}
+
+// Rope is a persistent sequence of UTF-8 encoded text stored as a height balanced
+// binary tree of string chunks. Insert, Delete, Replace, Slice and Concat all run in
+// O(log n) time and only allocate new nodes along the edited paths.
+//
+// Offsets accepted by the methods of a Rope are byte offsets and must fall on rune
+// boundaries, otherwise the methods will panic. Methods that accept rune indexes or
+// line numbers are named accordingly and ByteOffset converts rune indexes into byte
+// offsets.
+//
+// The zero value of a Rope is an empty rope ready to use. A Rope is safe for concurrent
+// use by multiple goroutines as none of its methods modify it.
+type Rope struct {
+	root *node
+}
+
+// New creates a rope holding the text of s.
+func New(s string) Rope {
+	return Rope{root: build(s)}
+}
+
+// Len returns the length of the text in bytes.
+func (r Rope) Len() int {
+	if r.root == nil {
+		return 0
+	}
+	return r.root.bytes
+}
+
+// RuneLen returns the number of runes in the text.
+func (r Rope) RuneLen() int {
+	if r.root == nil {
+		return 0
+	}
+	return r.root.runes
+}
+
+// LineCount returns the number of lines in the text. This is always one more than the
+// number of newline characters so an empty rope consists of a single empty line.
+func (r Rope) LineCount() int {
+	if r.root == nil {
+		return 1
+	}
+	return r.root.lines + 1
+}
+
+// String returns the full text of the rope.
+func (r Rope) String() string {
+	var b strings.Builder
+	b.Grow(r.Len())
+	r.Chunks(func(chunk string) bool {
+		b.WriteString(chunk)
+		return true
+	})
+	return b.String()
+}
+
+// Concat returns a new rope holding the text of r followed by the text of other.
+func (r Rope) Concat(other Rope) Rope {
+	return Rope{root: join(r.root, other.root)}
+}
+
+// Insert returns a new rope with s inserted at byte offset i.
+func (r Rope) Insert(i int, s string) Rope {
+	r.checkOffset(i)
+	left, right := split(r.root, i)
+	return Rope{root: join(join(left, build(s)), right)}
+}
+
+// Delete returns a new rope with the bytes from offset i up to but excluding offset j
+// removed.
+func (r Rope) Delete(i, j int) Rope {
+	r.checkRange(i, j)
+	left, rest := split(r.root, i)
+	_, right := split(rest, j-i)
+	return Rope{root: join(left, right)}
+}
+
+// Replace returns a new rope with the bytes from offset i up to but excluding offset j
+// replaced by s.
+func (r Rope) Replace(i, j int, s string) Rope {
+	r.checkRange(i, j)
+	left, rest := split(r.root, i)
+	_, right := split(rest, j-i)
+	return Rope{root: join(join(left, build(s)), right)}
+}
+
+// Slice returns a new rope holding the bytes from offset i up to but excluding offset j.
+func (r Rope) Slice(i, j int) Rope {
+	r.checkRange(i, j)
+	_, rest := split(r.root, i)
+	middle, _ := split(rest, j-i)
+	return Rope{root: middle}
+}
+
+// ByteAt returns the byte at offset i. It panics if i is out of range.
+func (r Rope) ByteAt(i int) byte {
+	if i < 0 || i >= r.Len() {
+		panic("immutablerope: byte offset out of range")
+	}
+
+	n := r.root
+	for !n.isLeaf() {
+		if i < n.left.bytes {
+			n = n.left
+		} else {
+			i -= n.left.bytes
+			n = n.right
+		}
+	}
+	return n.text[i]
+}
+
+// RuneAt returns the rune with index n, counting runes from the start of the text.
+// It panics if n is out of range.
+func (r Rope) RuneAt(n int) rune {
+	if n < 0 || n >= r.RuneLen() {
+		panic("immutablerope: rune index out of range")
+	}
+
+	leaf, offset := r.findRune(n)
+	v, _ := utf8.DecodeRuneInString(leaf.text[offset:])
+	return v
+}
+
+// ByteOffset converts a rune index into the byte offset at which that rune starts.
+// A rune index equal to RuneLen converts to Len. It panics if n is out of range.
+func (r Rope) ByteOffset(n int) int {
+	if n < 0 || n > r.RuneLen() {
+		panic("immutablerope: rune index out of range")
+	}
+	if n == r.RuneLen() {
+		return r.Len()
+	}
+
+	var base int
+	cur := r.root
+	for !cur.isLeaf() {
+		if n < cur.left.runes {
+			cur = cur.left
+		} else {
+			n -= cur.left.runes
+			base += cur.left.bytes
+			cur = cur.right
+		}
+	}
+	return base + runeOffset(cur.text, n)
+}
+
+// RuneIndex converts the byte offset i into the index of the rune starting at it.
+// It panics if i is out of range or does not fall on a rune boundary.
+func (r Rope) RuneIndex(i int) int {
+	r.checkOffset(i)
+	runes, _ := r.prefix(i)
+	return runes
+}
+
+// LineCol returns the zero based line number and column of the byte offset i. Columns
+// are counted in runes from the start of the line. It panics if i is out of range or
+// does not fall on a rune boundary.
+func (r Rope) LineCol(i int) (line, col int) {
+	r.checkOffset(i)
+	runes, line := r.prefix(i)
+	lineRunes, _ := r.prefix(r.LineStart(line))
+	return line, runes - lineRunes
+}
+
+// LineStart returns the byte offset at which the zero based line starts. It panics if
+// the line does not exist.
+func (r Rope) LineStart(line int) int {
+	if line < 0 || line >= r.LineCount() {
+		panic("immutablerope: line out of range")
+	}
+	if line == 0 {
+		return 0
+	}
+
+	// find the offset just past the line-th newline character
+	var base int
+	cur := r.root
+	for !cur.isLeaf() {
+		if line <= cur.left.lines {
+			cur = cur.left
+		} else {
+			line -= cur.left.lines
+			base += cur.left.bytes
+			cur = cur.right
+		}
+	}
+
+	text := cur.text
+	for ; line > 1; line-- {
+		idx := strings.IndexByte(text, '\n')
+		base += idx + 1
+		text = text[idx+1:]
+	}
+	return base + strings.IndexByte(text, '\n') + 1
+}
+
+// Offset converts a zero based line number and rune column into a byte offset. It panics
+// if the line does not exist or the column is beyond the end of the line.
+func (r Rope) Offset(line, col int) int {
+	start := r.LineStart(line)
+	end := r.Len()
+	if line+1 < r.LineCount() {
+		// exclude the newline terminating the line
+		end = r.LineStart(line+1) - 1
+	}
+
+	startRunes, _ := r.prefix(start)
+	endRunes, _ := r.prefix(end)
+	if col < 0 || col > endRunes-startRunes {
+		panic("immutablerope: column out of range")
+	}
+	return r.ByteOffset(startRunes + col)
+}
+
+// Chunks calls fn with each chunk of the text in order. Concatenating all the chunks
+// results in the full text. Iteration stops early if fn returns false.
+func (r Rope) Chunks(fn func(chunk string) bool) {
+	if r.root != nil {
+		chunks(r.root, fn)
+	}
+}
+
+// Runes calls fn for each rune of the text along with the byte offset at which it
+// starts. Invalid UTF-8 sequences are reported as utf8.RuneError just like ranging over
+// a string. Iteration stops early if fn returns false.
+func (r Rope) Runes(fn func(offset int, v rune) bool) {
+	base := 0
+	r.Chunks(func(chunk string) bool {
+		for i, v := range chunk {
+			if !fn(base+i, v) {
+				return false
+			}
+		}
+		base += len(chunk)
+		return true
+	})
+}
+
+// checkOffset panics if i is not a valid byte offset on a rune boundary.
+func (r Rope) checkOffset(i int) {
+	if i < 0 || i > r.Len() {
+		panic("immutablerope: byte offset out of range")
+	}
+	if i < r.Len() && !utf8.RuneStart(r.ByteAt(i)) {
+		panic("immutablerope: byte offset is not on a rune boundary")
+	}
+}
+
+// checkRange panics if i and j are not valid byte offsets on rune boundaries with
+// i <= j.
+func (r Rope) checkRange(i, j int) {
+	if i > j {
+		panic("immutablerope: invalid range")
+	}
+	r.checkOffset(i)
+	r.checkOffset(j)
+}
+
+// findRune locates the leaf holding the n-th rune along with the byte offset of that
+// rune within the leaf.
+func (r Rope) findRune(n int) (*node, int) {
+	cur := r.root
+	for !cur.isLeaf() {
+		if n < cur.left.runes {
+			cur = cur.left
+		} else {
+			n -= cur.left.runes
+			cur = cur.right
+		}
+	}
+	return cur, runeOffset(cur.text, n)
+}
+
+// prefix returns the number of runes and newline characters before byte offset i.
+func (r Rope) prefix(i int) (runes, lines int) {
+	cur := r.root
+	for cur != nil && !cur.isLeaf() {
+		if i < cur.left.bytes {
+			cur = cur.left
+		} else {
+			i -= cur.left.bytes
+			runes += cur.left.runes
+			lines += cur.left.lines
+			cur = cur.right
+		}
+	}
+
+	if cur != nil {
+		runes += utf8.RuneCountInString(cur.text[:i])
+		lines += strings.Count(cur.text[:i], "\n")
+	}
+	return runes, lines
+}
+
+// runeOffset returns the byte offset of the n-th rune of s.
+func runeOffset(s string, n int) int {
+	for i := range s {
+		if n == 0 {
+			return i
+		}
+		n -= 1
+	}
+	return len(s)
+}
+
+func chunks(n *node, fn func(string) bool) bool {
+	if n.isLeaf() {
+		return fn(n.text)
+	}
+	return chunks(n.left, fn) && chunks(n.right, fn)
+}
+
+// build creates a balanced tree from s by splitting it into leaves on rune boundaries.
+func build(s string) *node {
+	if s == "" {
+		return nil
+	}
+
+	var leaves []*node
+	for len(s) > 0 {
+		end := len(s)
+		if end > maxLeafLen {
+			end = maxLeafLen
+			// back up to the start of a rune so that no rune spans two leaves
+			for end > 0 && !utf8.RuneStart(s[end]) {
+				end -= 1
+			}
+			if end == 0 {
+				end = maxLeafLen
+			}
+		}
+		leaves = append(leaves, newLeaf(s[:end]))
+		s = s[end:]
+	}
+
+	return buildBalanced(leaves)
+}
+
+// buildBalanced joins the leaves into a tree by recursively splitting them in half which
+// keeps the heights of sibling subtrees within one of each other.
+func buildBalanced(leaves []*node) *node {
+	if len(leaves) == 1 {
+		return leaves[0]
+	}
+
+	mid := len(leaves) / 2
+	return newInner(buildBalanced(leaves[:mid]), buildBalanced(leaves[mid:]))
+}
+
+// split divides the tree into the text before byte offset i and the text after it.
+// Only the nodes along the path to the offset are copied.
+func split(n *node, i int) (*node, *node) {
+	if n == nil {
+		return nil, nil
+	}
+	if i == 0 {
+		return nil, n
+	}
+	if i == n.bytes {
+		return n, nil
+	}
+
+	if n.isLeaf() {
+		return newLeaf(n.text[:i]), newLeaf(n.text[i:])
+	}
+
+	if i <= n.left.bytes {
+		l, r := split(n.left, i)
+		return l, join(r, n.right)
+	}
+
+	l, r := split(n.right, i-n.left.bytes)
+	return join(n.left, l), r
+}
+
+// join concatenates two trees while keeping the result height balanced.
+func join(a, b *node) *node {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+
+	if a.isLeaf() && b.isLeaf() && a.bytes+b.bytes <= maxLeafLen {
+		return newLeaf(a.text + b.text)
+	}
+
+	switch ha, hb := a.height, b.height; {
+	case ha > hb+1:
+		return balance(a.left, join(a.right, b))
+	case hb > ha+1:
+		return balance(join(a, b.left), b.right)
+	default:
+		return newInner(a, b)
+	}
+}
+
+// balance creates an inner node from two subtrees whose heights differ by at most two,
+// rotating as necessary to restore the balance invariant.
+func balance(l, r *node) *node {
+	hl, hr := l.getHeight(), r.getHeight()
+	switch {
+	case hl > hr+1:
+		if l.left.getHeight() >= l.right.getHeight() {
+			return newInner(l.left, newInner(l.right, r))
+		}
+		return newInner(newInner(l.left, l.right.left), newInner(l.right.right, r))
+	case hr > hl+1:
+		if r.right.getHeight() >= r.left.getHeight() {
+			return newInner(newInner(l, r.left), r.right)
+		}
+		return newInner(newInner(l, r.left.left), newInner(r.left.right, r.right))
+	default:
+		return newInner(l, r)
+	}
+}
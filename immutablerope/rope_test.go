@@ -0,0 +1,310 @@
+package immutablerope
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/require"
+)
+
+// requireBalanced verifies the structural invariants of every node in the tree.
+func requireBalanced(t *testing.T, n *node) {
+	t.Helper()
+
+	if n == nil || n.isLeaf() {
+		return
+	}
+
+	require.NotNil(t, n.left)
+	require.NotNil(t, n.right)
+	require.LessOrEqual(t, n.left.height-n.right.height, 1)
+	require.LessOrEqual(t, n.right.height-n.left.height, 1)
+	require.Equal(t, n.left.bytes+n.right.bytes, n.bytes)
+	require.Equal(t, n.left.runes+n.right.runes, n.runes)
+	require.Equal(t, n.left.lines+n.right.lines, n.lines)
+	requireBalanced(t, n.left)
+	requireBalanced(t, n.right)
+}
+
+func TestNew(t *testing.T) {
+	type testCase struct {
+		text string
+	}
+
+	cases := map[string]testCase{
+		"empty": {
+			text: "",
+		},
+		"short": {
+			text: "hello world",
+		},
+		"multibyte": {
+			text: "héllo wörld ✓",
+		},
+		"larger than a leaf": {
+			text: strings.Repeat("abcdéfg\n", 1000),
+		},
+	}
+
+	for name, tcase := range cases {
+		tcase := tcase
+
+		t.Run(name, func(t *testing.T) {
+			r := New(tcase.text)
+			require.Equal(t, tcase.text, r.String())
+			require.Equal(t, len(tcase.text), r.Len())
+			require.Equal(t, utf8.RuneCountInString(tcase.text), r.RuneLen())
+			require.Equal(t, strings.Count(tcase.text, "\n")+1, r.LineCount())
+			requireBalanced(t, r.root)
+
+			r.Chunks(func(chunk string) bool {
+				require.True(t, utf8.ValidString(chunk))
+				return true
+			})
+		})
+	}
+}
+
+func TestEdits(t *testing.T) {
+	type testCase struct {
+		text     string
+		edit     func(Rope) Rope
+		expected string
+		panics   bool
+	}
+
+	cases := map[string]testCase{
+		"insert at start": {
+			text:     "world",
+			edit:     func(r Rope) Rope { return r.Insert(0, "hello ") },
+			expected: "hello world",
+		},
+		"insert at end": {
+			text:     "hello",
+			edit:     func(r Rope) Rope { return r.Insert(5, " world") },
+			expected: "hello world",
+		},
+		"insert into empty": {
+			text:     "",
+			edit:     func(r Rope) Rope { return r.Insert(0, "hello") },
+			expected: "hello",
+		},
+		"insert in the middle of a rune": {
+			text:   "é",
+			edit:   func(r Rope) Rope { return r.Insert(1, "x") },
+			panics: true,
+		},
+		"insert out of range": {
+			text:   "abc",
+			edit:   func(r Rope) Rope { return r.Insert(4, "x") },
+			panics: true,
+		},
+		"delete": {
+			text:     "hello cruel world",
+			edit:     func(r Rope) Rope { return r.Delete(5, 11) },
+			expected: "hello world",
+		},
+		"delete everything": {
+			text:     "hello",
+			edit:     func(r Rope) Rope { return r.Delete(0, 5) },
+			expected: "",
+		},
+		"delete inverted range": {
+			text:   "hello",
+			edit:   func(r Rope) Rope { return r.Delete(3, 2) },
+			panics: true,
+		},
+		"replace": {
+			text:     "hello world",
+			edit:     func(r Rope) Rope { return r.Replace(6, 11, "wörld") },
+			expected: "hello wörld",
+		},
+		"slice": {
+			text:     "hello wörld",
+			edit:     func(r Rope) Rope { return r.Slice(6, 12) },
+			expected: "wörld",
+		},
+		"concat": {
+			text:     "hello",
+			edit:     func(r Rope) Rope { return r.Concat(New(" world")) },
+			expected: "hello world",
+		},
+	}
+
+	for name, tcase := range cases {
+		tcase := tcase
+
+		t.Run(name, func(t *testing.T) {
+			r := New(tcase.text)
+
+			if tcase.panics {
+				require.Panics(t, func() {
+					tcase.edit(r)
+				})
+				return
+			}
+
+			actual := tcase.edit(r)
+			require.Equal(t, tcase.expected, actual.String())
+			requireBalanced(t, actual.root)
+
+			// the original rope must be unmodified
+			require.Equal(t, tcase.text, r.String())
+		})
+	}
+}
+
+func TestRandomEdits(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	alphabet := []rune("abc é✓\n")
+
+	randomText := func(n int) string {
+		var b strings.Builder
+		for i := 0; i < n; i++ {
+			b.WriteRune(alphabet[rnd.Intn(len(alphabet))])
+		}
+		return b.String()
+	}
+
+	// pick a random byte offset on a rune boundary
+	randomOffset := func(s string) int {
+		runes := utf8.RuneCountInString(s)
+		n := rnd.Intn(runes + 1)
+		for i := range s {
+			if n == 0 {
+				return i
+			}
+			n -= 1
+		}
+		return len(s)
+	}
+
+	model := randomText(2000)
+	r := New(model)
+	versions := map[string]Rope{model: r}
+
+	for i := 0; i < 500; i++ {
+		a, b := randomOffset(model), randomOffset(model)
+		if a > b {
+			a, b = b, a
+		}
+
+		switch rnd.Intn(3) {
+		case 0:
+			text := randomText(rnd.Intn(600))
+			r = r.Insert(a, text)
+			model = model[:a] + text + model[a:]
+		case 1:
+			r = r.Delete(a, b)
+			model = model[:a] + model[b:]
+		default:
+			text := randomText(rnd.Intn(20))
+			r = r.Replace(a, b, text)
+			model = model[:a] + text + model[b:]
+		}
+
+		require.Equal(t, model, r.String())
+		requireBalanced(t, r.root)
+		versions[model] = r
+	}
+
+	// every previous version is still intact
+	for text, v := range versions {
+		require.Equal(t, text, v.String())
+	}
+}
+
+func TestIndexing(t *testing.T) {
+	text := strings.Repeat("aé✓\n", 300)
+	r := New(text)
+	runes := []rune(text)
+
+	offset := 0
+	for n, v := range runes {
+		require.Equal(t, v, r.RuneAt(n))
+		require.Equal(t, offset, r.ByteOffset(n))
+		require.Equal(t, n, r.RuneIndex(offset))
+		require.Equal(t, text[offset], r.ByteAt(offset))
+		offset += utf8.RuneLen(v)
+	}
+	require.Equal(t, len(text), r.ByteOffset(len(runes)))
+
+	require.Panics(t, func() { r.RuneAt(len(runes)) })
+	require.Panics(t, func() { r.ByteAt(len(text)) })
+	require.Panics(t, func() { r.RuneIndex(2) })
+}
+
+func TestLines(t *testing.T) {
+	text := "first\nsécond line\n\nlast ✓"
+	r := New(text)
+
+	require.Equal(t, 4, r.LineCount())
+	require.Equal(t, 0, r.LineStart(0))
+	require.Equal(t, 6, r.LineStart(1))
+	require.Equal(t, 19, r.LineStart(2))
+	require.Equal(t, 20, r.LineStart(3))
+	require.Panics(t, func() { r.LineStart(4) })
+
+	type position struct {
+		offset, line, col int
+	}
+
+	for _, p := range []position{
+		{offset: 0, line: 0, col: 0},
+		{offset: 5, line: 0, col: 5},
+		{offset: 6, line: 1, col: 0},
+		{offset: 9, line: 1, col: 2},
+		{offset: 19, line: 2, col: 0},
+		{offset: 25, line: 3, col: 5},
+		{offset: len(text), line: 3, col: 6},
+	} {
+		line, col := r.LineCol(p.offset)
+		require.Equal(t, p.line, line, "line of offset %d", p.offset)
+		require.Equal(t, p.col, col, "column of offset %d", p.offset)
+		require.Equal(t, p.offset, r.Offset(p.line, p.col))
+	}
+
+	require.Panics(t, func() { r.Offset(2, 1) })
+}
+
+func TestLinesAcrossLeaves(t *testing.T) {
+	text := strings.Repeat("0123456789\n", 500)
+	r := New(text)
+
+	for line := 0; line < r.LineCount(); line++ {
+		require.Equal(t, line*11, r.LineStart(line))
+	}
+
+	l, c := r.LineCol(11*321 + 7)
+	require.Equal(t, 321, l)
+	require.Equal(t, 7, c)
+}
+
+func TestRunes(t *testing.T) {
+	text := strings.Repeat("hé✓", 400)
+	r := New(text)
+
+	type entry struct {
+		offset int
+		v      rune
+	}
+
+	var expected, actual []entry
+	for i, v := range text {
+		expected = append(expected, entry{i, v})
+	}
+	r.Runes(func(offset int, v rune) bool {
+		actual = append(actual, entry{offset, v})
+		return true
+	})
+	require.Equal(t, expected, actual)
+
+	count := 0
+	r.Runes(func(int, rune) bool {
+		count += 1
+		return count < 10
+	})
+	require.Equal(t, 10, count)
+}
@@ -0,0 +1,90 @@
+package immutableslice
+
+import (
+	"sync/atomic"
+	"unsafe"
+)
+
+// Atomic holds a slice which can be loaded and replaced atomically. It is meant for
+// publishing immutable slices to many goroutines: writers build a new version of the
+// slice with the functions of this package and then store it, while readers load the
+// current version without any locking. As a stored slice is only ever replaced and never
+// modified, readers always observe a complete version.
+//
+// Slices passed to Store, Swap and CompareAndSwap or returned by the functions given to
+// Update must not be modified afterwards, and neither may slices returned by Load.
+//
+// Go cannot infer the element type of a generic type, so both type parameters must be
+// specified, e.g. Atomic[[]string, string]. The zero value of an Atomic holds a nil
+// slice and is ready to use. An Atomic must not be copied after first use.
+type Atomic[S ~[]E, E any] struct {
+	v atomic.Pointer[S]
+}
+
+// Load returns the current slice.
+func (a *Atomic[S, E]) Load() S {
+	if p := a.v.Load(); p != nil {
+		return *p
+	}
+	return nil
+}
+
+// Store replaces the current slice with s.
+func (a *Atomic[S, E]) Store(s S) {
+	a.v.Store(&s)
+}
+
+// Swap replaces the current slice with s and returns the slice it replaced.
+func (a *Atomic[S, E]) Swap(s S) S {
+	if p := a.v.Swap(&s); p != nil {
+		return *p
+	}
+	return nil
+}
+
+// CompareAndSwap replaces the current slice with new if the current slice is old and
+// returns whether the replacement happened. Two slices are considered the same when
+// they refer to the same backing array with the same length and capacity, which is the
+// case for a slice previously returned by Load.
+func (a *Atomic[S, E]) CompareAndSwap(old, new S) bool {
+	for {
+		p := a.v.Load()
+		var current S
+		if p != nil {
+			current = *p
+		}
+		if !sameSlice(current, old) {
+			return false
+		}
+		if a.v.CompareAndSwap(p, &new) {
+			return true
+		}
+		// another goroutine stored a slice in between, which may still be the same
+		// slice as old so check again.
+	}
+}
+
+// Update replaces the current slice with the result of calling fn with it and returns
+// the newly stored slice. If another goroutine replaces the slice while fn is running,
+// fn is called again with the newer slice until its result can be stored without
+// overwriting a concurrent change. As fn may be called multiple times, it should not
+// have side effects.
+func (a *Atomic[S, E]) Update(fn func(S) S) S {
+	for {
+		p := a.v.Load()
+		var current S
+		if p != nil {
+			current = *p
+		}
+
+		updated := fn(current)
+		if a.v.CompareAndSwap(p, &updated) {
+			return updated
+		}
+	}
+}
+
+// sameSlice returns whether a and b refer to the same elements of the same backing array.
+func sameSlice[S ~[]E, E any](a, b S) bool {
+	return unsafe.SliceData([]E(a)) == unsafe.SliceData([]E(b)) && len(a) == len(b) && cap(a) == cap(b)
+}
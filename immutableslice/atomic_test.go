@@ -0,0 +1,93 @@
+package immutableslice
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAtomic(t *testing.T) {
+	var a Atomic[[]int, int]
+	require.Nil(t, a.Load())
+
+	first := []int{1, 2, 3}
+	a.Store(first)
+	require.Equal(t, first, a.Load())
+
+	second := Append(a.Load(), 4)
+	require.Equal(t, first, a.Swap(second))
+	require.Equal(t, []int{1, 2, 3, 4}, a.Load())
+
+	// a slice holding equal elements but a different backing array is not the same slice
+	require.False(t, a.CompareAndSwap([]int{1, 2, 3, 4}, nil))
+	// neither is a shorter view of the same backing array
+	require.False(t, a.CompareAndSwap(second[:3], nil))
+	require.Equal(t, []int{1, 2, 3, 4}, a.Load())
+
+	require.True(t, a.CompareAndSwap(a.Load(), first))
+	require.Equal(t, first, a.Load())
+
+	updated := a.Update(func(s []int) []int { return Delete(s, 0, 1) })
+	require.Equal(t, []int{2, 3}, updated)
+	require.Equal(t, updated, a.Load())
+
+	// the slices that were replaced are unaffected
+	require.Equal(t, []int{1, 2, 3}, first)
+	require.Equal(t, []int{1, 2, 3, 4}, second)
+}
+
+func TestAtomicZeroValue(t *testing.T) {
+	var a Atomic[[]string, string]
+	require.True(t, a.CompareAndSwap(nil, []string{"a"}))
+	require.Equal(t, []string{"a"}, a.Load())
+
+	var b Atomic[[]string, string]
+	require.Nil(t, b.Swap([]string{"b"}))
+}
+
+func TestAtomicConcurrentUpdate(t *testing.T) {
+	const (
+		writers = 8
+		appends = 100
+	)
+
+	var a Atomic[[]int, int]
+	var wg sync.WaitGroup
+
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < appends; i++ {
+				a.Update(func(s []int) []int { return Append(s, w) })
+			}
+		}(w)
+	}
+
+	// readers only ever see complete versions where every element was written
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for len(a.Load()) < writers*appends {
+			for _, v := range a.Load() {
+				if v < 0 || v >= writers {
+					t.Errorf("observed unexpected element %d", v)
+					return
+				}
+			}
+		}
+	}()
+
+	wg.Wait()
+	<-done
+
+	counts := make(map[int]int)
+	for _, v := range a.Load() {
+		counts[v] += 1
+	}
+	require.Len(t, counts, writers)
+	for w := 0; w < writers; w++ {
+		require.Equal(t, appends, counts[w])
+	}
+}
@@ -0,0 +1,168 @@
+package immutableslice
+
+import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"encoding"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+)
+
+// maxLogElems is the number of elements a Frozen slice includes when it is logged.
+const maxLogElems = 10
+
+var (
+	_ json.Marshaler             = Frozen[int]{}
+	_ json.Unmarshaler           = (*Frozen[int])(nil)
+	_ encoding.TextMarshaler     = Frozen[int]{}
+	_ encoding.TextUnmarshaler   = (*Frozen[int])(nil)
+	_ encoding.BinaryMarshaler   = Frozen[int]{}
+	_ encoding.BinaryUnmarshaler = (*Frozen[int])(nil)
+	_ sql.Scanner                = (*Frozen[int])(nil)
+	_ driver.Valuer              = Frozen[int]{}
+	_ slog.LogValuer             = Frozen[int]{}
+)
+
+// Frozen is a slice whose elements cannot be modified. The elements are kept in an
+// unexported slice which is never handed out directly: Freeze copies the elements in and
+// Slice copies them back out, so no caller can get hold of the backing array.
+//
+// Frozen implements the JSON, text and binary encoding interfaces along with the
+// database/sql Scanner and driver.Valuer interfaces so that slices keep their guarantees
+// when crossing service or storage boundaries. Decoding always replaces the backing
+// array instead of writing into it, so other copies of a Frozen value are unaffected.
+//
+// The zero value of a Frozen is an empty slice ready to use. A Frozen is safe for
+// concurrent use by multiple goroutines as none of its value methods modify it.
+type Frozen[E any] struct {
+	elems []E
+}
+
+// Freeze creates a Frozen slice holding a copy of the elements of s.
+func Freeze[S ~[]E, E any](s S) Frozen[E] {
+	if len(s) == 0 {
+		return Frozen[E]{}
+	}
+	return Frozen[E]{elems: slices.Clone([]E(s))}
+}
+
+// Len returns the number of elements in the slice.
+func (f Frozen[E]) Len() int {
+	return len(f.elems)
+}
+
+// At returns the element at index i. It panics if i is out of range.
+func (f Frozen[E]) At(i int) E {
+	return f.elems[i]
+}
+
+// Range calls fn for each element of the slice in order along with its index. Iteration
+// stops early if fn returns false.
+func (f Frozen[E]) Range(fn func(int, E) bool) {
+	for i, e := range f.elems {
+		if !fn(i, e) {
+			return
+		}
+	}
+}
+
+// Slice returns a newly allocated slice holding the elements. An empty Frozen slice
+// results in a nil slice.
+func (f Frozen[E]) Slice() []E {
+	if len(f.elems) == 0 {
+		return nil
+	}
+	return slices.Clone(f.elems)
+}
+
+// MarshalJSON encodes the elements as a JSON array. An empty slice encodes as an empty
+// array rather than null so that the encoding does not depend on how the slice was
+// created.
+func (f Frozen[E]) MarshalJSON() ([]byte, error) {
+	if len(f.elems) == 0 {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(f.elems)
+}
+
+// UnmarshalJSON decodes a JSON array into a newly allocated slice. A JSON null decodes as
+// an empty slice.
+func (f *Frozen[E]) UnmarshalJSON(data []byte) error {
+	var elems []E
+	if err := json.Unmarshal(data, &elems); err != nil {
+		return err
+	}
+	*f = Freeze(elems)
+	return nil
+}
+
+// MarshalText encodes the elements as a JSON array.
+func (f Frozen[E]) MarshalText() ([]byte, error) {
+	return f.MarshalJSON()
+}
+
+// UnmarshalText decodes a JSON array into a newly allocated slice.
+func (f *Frozen[E]) UnmarshalText(data []byte) error {
+	return f.UnmarshalJSON(data)
+}
+
+// MarshalBinary encodes the elements using encoding/gob. The element type must therefore
+// be encodable by gob.
+func (f Frozen[E]) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(f.elems); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary decodes elements encoded by MarshalBinary into a newly allocated slice.
+func (f *Frozen[E]) UnmarshalBinary(data []byte) error {
+	var elems []E
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&elems); err != nil {
+		return err
+	}
+	*f = Freeze(elems)
+	return nil
+}
+
+// Scan implements the database/sql Scanner interface. The source value must hold a JSON
+// array either as a []byte or a string. A NULL value scans as an empty slice.
+func (f *Frozen[E]) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*f = Frozen[E]{}
+		return nil
+	case []byte:
+		return f.UnmarshalJSON(v)
+	case string:
+		return f.UnmarshalJSON([]byte(v))
+	default:
+		return fmt.Errorf("immutableslice: cannot scan %T into Frozen", src)
+	}
+}
+
+// Value implements the database/sql/driver Valuer interface by encoding the elements as
+// a JSON array.
+func (f Frozen[E]) Value() (driver.Value, error) {
+	return f.MarshalJSON()
+}
+
+// LogValue implements the slog.LogValuer interface. The slice is logged as a group
+// holding its length and up to the first 10 elements. When elements were left out, the
+// group additionally holds a truncated attribute set to true.
+func (f Frozen[E]) LogValue() slog.Value {
+	attrs := []slog.Attr{slog.Int("len", len(f.elems))}
+	if len(f.elems) <= maxLogElems {
+		return slog.GroupValue(append(attrs, slog.Any("elems", f.Slice()))...)
+	}
+
+	return slog.GroupValue(append(attrs,
+		slog.Any("elems", slices.Clone(f.elems[:maxLogElems])),
+		slog.Bool("truncated", true),
+	)...)
+}
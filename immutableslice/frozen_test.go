@@ -0,0 +1,184 @@
+package immutableslice
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFreeze(t *testing.T) {
+	s := []int{1, 2, 3}
+	f := Freeze(s)
+
+	// modifying the input does not affect the frozen slice
+	s[0] = 10
+	require.Equal(t, 3, f.Len())
+	require.Equal(t, 1, f.At(0))
+
+	// modifying the output does not affect the frozen slice
+	out := f.Slice()
+	out[1] = 20
+	require.Equal(t, []int{1, 2, 3}, f.Slice())
+
+	var visited []int
+	f.Range(func(i int, e int) bool {
+		visited = append(visited, e)
+		return i < 1
+	})
+	require.Equal(t, []int{1, 2}, visited)
+
+	require.Nil(t, Freeze([]int{}).Slice())
+	require.Nil(t, Frozen[int]{}.Slice())
+	require.Panics(t, func() { f.At(3) })
+}
+
+func TestFrozenEncoding(t *testing.T) {
+	type encoding struct {
+		marshal   func(Frozen[string]) ([]byte, error)
+		unmarshal func(*Frozen[string], []byte) error
+	}
+
+	encodings := map[string]encoding{
+		"json":   {Frozen[string].MarshalJSON, (*Frozen[string]).UnmarshalJSON},
+		"text":   {Frozen[string].MarshalText, (*Frozen[string]).UnmarshalText},
+		"binary": {Frozen[string].MarshalBinary, (*Frozen[string]).UnmarshalBinary},
+		"sql": {
+			func(f Frozen[string]) ([]byte, error) {
+				v, err := f.Value()
+				if err != nil {
+					return nil, err
+				}
+				return v.([]byte), nil
+			},
+			func(f *Frozen[string], data []byte) error { return f.Scan(data) },
+		},
+	}
+
+	values := map[string][]string{
+		"empty":     nil,
+		"non-empty": {"a", "b", "c"},
+	}
+
+	for encName, enc := range encodings {
+		enc := enc
+
+		for valName, value := range values {
+			value := value
+
+			t.Run(encName+" "+valName, func(t *testing.T) {
+				data, err := enc.marshal(Freeze(value))
+				require.NoError(t, err)
+
+				// decoding replaces the backing array rather than writing into it
+				existing := Freeze([]string{"x", "y", "z"})
+				decoded := existing
+				require.NoError(t, enc.unmarshal(&decoded, data))
+				require.Equal(t, value, decoded.Slice())
+				require.Equal(t, []string{"x", "y", "z"}, existing.Slice())
+			})
+		}
+	}
+}
+
+func TestFrozenJSONField(t *testing.T) {
+	type config struct {
+		Name  string
+		Hosts Frozen[string]
+	}
+
+	var c config
+	require.NoError(t, json.Unmarshal([]byte(`{"Name":"web","Hosts":["a","b"]}`), &c))
+	require.Equal(t, []string{"a", "b"}, c.Hosts.Slice())
+
+	data, err := json.Marshal(c)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"Name":"web","Hosts":["a","b"]}`, string(data))
+
+	require.Error(t, json.Unmarshal([]byte(`{"Hosts":{"a":1}}`), &c))
+}
+
+func TestFrozenScan(t *testing.T) {
+	type testCase struct {
+		src      any
+		expected []int
+		err      bool
+	}
+
+	cases := map[string]testCase{
+		"nil": {
+			src:      nil,
+			expected: nil,
+		},
+		"bytes": {
+			src:      []byte("[1,2]"),
+			expected: []int{1, 2},
+		},
+		"string": {
+			src:      "[3]",
+			expected: []int{3},
+		},
+		"invalid json": {
+			src: "[1,",
+			err: true,
+		},
+		"unsupported type": {
+			src: 42,
+			err: true,
+		},
+	}
+
+	for name, tcase := range cases {
+		tcase := tcase
+
+		t.Run(name, func(t *testing.T) {
+			f := Freeze([]int{9})
+			err := f.Scan(tcase.src)
+			if tcase.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tcase.expected, f.Slice())
+		})
+	}
+}
+
+func TestFrozenLogValue(t *testing.T) {
+	type testCase struct {
+		elems    []int
+		expected string
+	}
+
+	cases := map[string]testCase{
+		"short": {
+			elems:    []int{1, 2, 3},
+			expected: "s.len=3 s.elems=\"[1 2 3]\"\n",
+		},
+		"truncated": {
+			elems:    []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12},
+			expected: "s.len=12 s.elems=\"[1 2 3 4 5 6 7 8 9 10]\" s.truncated=true\n",
+		},
+	}
+
+	for name, tcase := range cases {
+		tcase := tcase
+
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+				ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+					if len(groups) == 0 && a.Key != "s" {
+						return slog.Attr{}
+					}
+					return a
+				},
+			}))
+
+			logger.Info("", "s", Freeze(tcase.elems))
+			require.Equal(t, tcase.expected, buf.String())
+		})
+	}
+}
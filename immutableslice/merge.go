@@ -0,0 +1,254 @@
+package immutableslice
+
+import (
+	"slices"
+)
+
+// Conflict describes a range of the base slice which was changed differently by both
+// sides of a three-way merge. Base holds the elements of base[BaseStart:BaseEnd] while
+// Ours and Theirs hold what each side replaced that range with.
+type Conflict[E any] struct {
+	BaseStart int
+	BaseEnd   int
+	Base      []E
+	Ours      []E
+	Theirs    []E
+}
+
+// hunk replaces the elements base[start:end] with values.
+type hunk[E any] struct {
+	start, end int
+	values     []E
+	theirs     bool
+}
+
+// Merge3 combines the changes that ours and theirs made to base. Elements are compared
+// with ==. See Merge3Func for details.
+func Merge3[S ~[]E, E comparable](base, ours, theirs S) (S, []Conflict[E]) {
+	return Merge3Func(base, ours, theirs, func(a, b E) bool { return a == b })
+}
+
+// Merge3Func combines the changes that ours and theirs made to base, using the eq
+// function to compare elements. Both sides are diffed against base and their changes
+// are applied together. Changes made by only one side, or made identically by both,
+// are merged automatically. Changes of both sides which overlap, or which insert
+// elements at the same position, are conflicts.
+//
+// If there are no conflicts, the merged slice is returned along with a nil slice of
+// conflicts. Otherwise the merged slice is nil and every conflict is reported in the
+// order of its position in base. The merged slice is backed by a fresh array and none
+// of the input slices are modified.
+func Merge3Func[S ~[]E, E any](base, ours, theirs S, eq func(a, b E) bool) (S, []Conflict[E]) {
+	return Merge3Resolve(base, ours, theirs, eq, nil)
+}
+
+// Merge3Resolve is like Merge3Func but calls resolve for each conflict. The elements
+// resolve returns replace the conflicting range when its boolean return value is true.
+// Otherwise the conflict is reported as unresolved. The resolve function may be nil, in
+// which case all conflicts are unresolved.
+func Merge3Resolve[S ~[]E, E any](base, ours, theirs S, eq func(a, b E) bool, resolve func(Conflict[E]) ([]E, bool)) (S, []Conflict[E]) {
+	hunks := Concat(diff(base, ours, eq, false), diff(base, theirs, eq, true))
+	slices.SortStableFunc(hunks, func(a, b hunk[E]) int {
+		if a.start != b.start {
+			return a.start - b.start
+		}
+		return a.end - b.end
+	})
+
+	var merged S
+	var conflicts []Conflict[E]
+	cursor := 0
+	for i := 0; i < len(hunks); {
+		// group all hunks which overlap with each other
+		lo, hi := hunks[i].start, hunks[i].end
+		j := i + 1
+		for j < len(hunks) && (hunks[j].start < hi || hunks[j].start == lo) {
+			hi = max(hi, hunks[j].end)
+			j += 1
+		}
+		group := hunks[i:j]
+		i = j
+
+		merged = append(merged, base[cursor:lo]...)
+		cursor = hi
+
+		oursValues, oursChanged := applyHunks(base, lo, hi, group, false)
+		theirsValues, theirsChanged := applyHunks(base, lo, hi, group, true)
+		switch {
+		case !theirsChanged:
+			merged = append(merged, oursValues...)
+		case !oursChanged || slices.EqualFunc(oursValues, theirsValues, eq):
+			merged = append(merged, theirsValues...)
+		default:
+			c := Conflict[E]{
+				BaseStart: lo,
+				BaseEnd:   hi,
+				Base:      Concat(base[lo:hi]),
+				Ours:      oursValues,
+				Theirs:    theirsValues,
+			}
+			if resolve != nil {
+				if resolution, ok := resolve(c); ok {
+					merged = append(merged, resolution...)
+					continue
+				}
+			}
+			conflicts = append(conflicts, c)
+		}
+	}
+
+	if len(conflicts) > 0 {
+		return nil, conflicts
+	}
+
+	merged = append(merged, base[cursor:]...)
+	if len(merged) == 0 {
+		return nil, nil
+	}
+	return merged, nil
+}
+
+// applyHunks returns the elements that one side of the merge replaced base[lo:hi] with,
+// along with whether that side changed the range at all.
+func applyHunks[S ~[]E, E any](base S, lo, hi int, group []hunk[E], theirs bool) ([]E, bool) {
+	var out []E
+	changed := false
+	cursor := lo
+	for _, h := range group {
+		if h.theirs != theirs {
+			continue
+		}
+		changed = true
+		out = append(out, base[cursor:h.start]...)
+		out = append(out, h.values...)
+		cursor = h.end
+	}
+	out = append(out, base[cursor:hi]...)
+	return out, changed
+}
+
+// diff computes the hunks which turn base into other with the linear space variant of
+// Myers' algorithm, which runs in O((n+m)·d) time for inputs of lengths n and m differing
+// by d elements.
+func diff[S ~[]E, E any](base, other S, eq func(a, b E) bool, theirs bool) []hunk[E] {
+	// the furthest reaching paths are indexed by diagonal, which range over
+	// [-max-1, max+1] for the largest number of edits max searched by middleSnake
+	offset := (len(base)+len(other)+1)/2 + 1
+	d := differ[E]{
+		a:       base,
+		b:       other,
+		eq:      eq,
+		theirs:  theirs,
+		forward: make([]int, 2*offset+1),
+		reverse: make([]int, 2*offset+1),
+		offset:  offset,
+	}
+	d.compare(0, len(base), 0, len(other))
+	return d.hunks
+}
+
+// differ holds the state of a diff between a and b.
+type differ[E any] struct {
+	a, b   []E
+	eq     func(a, b E) bool
+	theirs bool
+	hunks  []hunk[E]
+
+	// forward and reverse hold the furthest x reached on each diagonal by the paths
+	// searching from the start and from the end of the compared ranges
+	forward []int
+	reverse []int
+	offset  int
+}
+
+// compare adds the hunks which turn a[aLo:aHi] into b[bLo:bHi].
+func (d *differ[E]) compare(aLo, aHi, bLo, bHi int) {
+	for aLo < aHi && bLo < bHi && d.eq(d.a[aLo], d.b[bLo]) {
+		aLo += 1
+		bLo += 1
+	}
+	for aLo < aHi && bLo < bHi && d.eq(d.a[aHi-1], d.b[bHi-1]) {
+		aHi -= 1
+		bHi -= 1
+	}
+
+	if aLo == aHi || bLo == bHi {
+		if aLo < aHi || bLo < bHi {
+			d.change(aLo, aHi, d.b[bLo:bHi])
+		}
+		return
+	}
+
+	// both ranges differ in their first and last elements so at least two edits are
+	// needed, which the middle snake splits between both halves
+	x, y, u, v := d.middleSnake(aLo, aHi, bLo, bHi)
+	d.compare(aLo, x, bLo, y)
+	d.compare(u, aHi, v, bHi)
+}
+
+// middleSnake returns the start (x, y) and end (u, v) of the snake in the middle of a
+// shortest edit script turning a[aLo:aHi] into b[bLo:bHi].
+func (d *differ[E]) middleSnake(aLo, aHi, bLo, bHi int) (x, y, u, v int) {
+	n, m := aHi-aLo, bHi-bLo
+	delta := n - m
+	odd := delta%2 != 0
+	fwd, rev, off := d.forward, d.reverse, d.offset
+
+	fwd[off+1] = 0
+	rev[off+1] = 0
+	for edits := 0; edits <= (n+m+1)/2; edits++ {
+		for k := -edits; k <= edits; k += 2 {
+			var x int
+			if k == -edits || (k != edits && fwd[off+k-1] < fwd[off+k+1]) {
+				x = fwd[off+k+1]
+			} else {
+				x = fwd[off+k-1] + 1
+			}
+			y := x - k
+			startX, startY := x, y
+			for x < n && y < m && d.eq(d.a[aLo+x], d.b[bLo+y]) {
+				x += 1
+				y += 1
+			}
+			fwd[off+k] = x
+
+			// the reverse path on the same diagonal has made edits-1 edits
+			if r := delta - k; odd && r >= -(edits-1) && r <= edits-1 && x+rev[off+r] >= n {
+				return aLo + startX, bLo + startY, aLo + x, bLo + y
+			}
+		}
+
+		for k := -edits; k <= edits; k += 2 {
+			var x int
+			if k == -edits || (k != edits && rev[off+k-1] < rev[off+k+1]) {
+				x = rev[off+k+1]
+			} else {
+				x = rev[off+k-1] + 1
+			}
+			y := x - k
+			startX, startY := x, y
+			for x < n && y < m && d.eq(d.a[aHi-1-x], d.b[bHi-1-y]) {
+				x += 1
+				y += 1
+			}
+			rev[off+k] = x
+
+			if f := delta - k; !odd && f >= -edits && f <= edits && x+fwd[off+f] >= n {
+				return aHi - x, bHi - y, aHi - startX, bHi - startY
+			}
+		}
+	}
+	panic("immutableslice: no middle snake found")
+}
+
+// change adds a hunk replacing a[start:end] with values, extending the previous hunk if
+// they are adjacent.
+func (d *differ[E]) change(start, end int, values []E) {
+	if n := len(d.hunks); n > 0 && d.hunks[n-1].end == start {
+		last := &d.hunks[n-1]
+		last.end = end
+		last.values = append(last.values, values...)
+		return
+	}
+	d.hunks = append(d.hunks, hunk[E]{start: start, end: end, values: append([]E(nil), values...), theirs: d.theirs})
+}
@@ -0,0 +1,265 @@
+package immutableslice
+
+import (
+	"math/rand"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMerge3(t *testing.T) {
+	type testCase struct {
+		base      []string
+		ours      []string
+		theirs    []string
+		expected  []string
+		conflicts []Conflict[string]
+	}
+
+	base := []string{"a", "b", "c", "d", "e"}
+
+	cases := map[string]testCase{
+		"no changes": {
+			base:     base,
+			ours:     base,
+			theirs:   base,
+			expected: base,
+		},
+		"only ours changed": {
+			base:     base,
+			ours:     []string{"a", "x", "c", "d", "e"},
+			theirs:   base,
+			expected: []string{"a", "x", "c", "d", "e"},
+		},
+		"only theirs changed": {
+			base:     base,
+			ours:     base,
+			theirs:   []string{"a", "b", "d", "e", "f"},
+			expected: []string{"a", "b", "d", "e", "f"},
+		},
+		"non-overlapping changes": {
+			base:     base,
+			ours:     []string{"x", "a", "b", "c", "d", "e"},
+			theirs:   []string{"a", "b", "c", "y", "e"},
+			expected: []string{"x", "a", "b", "c", "y", "e"},
+		},
+		"adjacent delete and insert": {
+			base:     base,
+			ours:     []string{"a", "d", "e"},
+			theirs:   []string{"a", "b", "c", "x", "d", "e"},
+			expected: []string{"a", "x", "d", "e"},
+		},
+		"identical changes": {
+			base:     base,
+			ours:     []string{"a", "x", "c", "e"},
+			theirs:   []string{"a", "x", "c", "e"},
+			expected: []string{"a", "x", "c", "e"},
+		},
+		"everything deleted": {
+			base:     base,
+			ours:     nil,
+			theirs:   base,
+			expected: nil,
+		},
+		"deletion overlapping a change": {
+			base:   base,
+			ours:   []string{"a", "e"},
+			theirs: []string{"a", "b", "x", "d", "e"},
+			conflicts: []Conflict[string]{
+				{
+					BaseStart: 1,
+					BaseEnd:   4,
+					Base:      []string{"b", "c", "d"},
+					Ours:      nil,
+					Theirs:    []string{"b", "x", "d"},
+				},
+			},
+		},
+		"overlapping changes": {
+			base:   base,
+			ours:   []string{"a", "x", "c", "d", "e"},
+			theirs: []string{"a", "y", "z", "d", "e"},
+			conflicts: []Conflict[string]{
+				{
+					BaseStart: 1,
+					BaseEnd:   3,
+					Base:      []string{"b", "c"},
+					Ours:      []string{"x", "c"},
+					Theirs:    []string{"y", "z"},
+				},
+			},
+		},
+		"insertions at the same position": {
+			base:   base,
+			ours:   []string{"a", "b", "x", "c", "d", "e"},
+			theirs: []string{"a", "b", "y", "c", "d", "e", "z"},
+			conflicts: []Conflict[string]{
+				{
+					BaseStart: 2,
+					BaseEnd:   2,
+					Ours:      []string{"x"},
+					Theirs:    []string{"y"},
+				},
+			},
+		},
+		"multiple conflicts": {
+			base:   base,
+			ours:   []string{"x", "b", "c", "d"},
+			theirs: []string{"y", "b", "c", "d", "f"},
+			conflicts: []Conflict[string]{
+				{
+					BaseStart: 0,
+					BaseEnd:   1,
+					Base:      []string{"a"},
+					Ours:      []string{"x"},
+					Theirs:    []string{"y"},
+				},
+				{
+					BaseStart: 4,
+					BaseEnd:   5,
+					Base:      []string{"e"},
+					Ours:      nil,
+					Theirs:    []string{"f"},
+				},
+			},
+		},
+		"empty base": {
+			base:     nil,
+			ours:     []string{"a"},
+			theirs:   nil,
+			expected: []string{"a"},
+		},
+	}
+
+	for name, tcase := range cases {
+		tcase := tcase
+
+		t.Run(name, func(t *testing.T) {
+			// clone the values to isolate any immutability issues to a single test case
+			base := slices.Clone(tcase.base)
+			ours := slices.Clone(tcase.ours)
+			theirs := slices.Clone(tcase.theirs)
+
+			actual, conflicts := Merge3(base, ours, theirs)
+			require.Equal(t, tcase.expected, actual)
+			require.Equal(t, tcase.conflicts, conflicts)
+
+			require.Equal(t, tcase.base, base)
+			require.Equal(t, tcase.ours, ours)
+			require.Equal(t, tcase.theirs, theirs)
+			if len(actual) > 0 {
+				for _, s := range [][]string{base, ours, theirs} {
+					if len(s) > 0 {
+						require.NotSame(t, &s[0], &actual[0])
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestMerge3Func(t *testing.T) {
+	base := []string{"a", "b", "c"}
+	ours := []string{"A", "b", "c", "d"}
+	theirs := []string{"a", "B", "c"}
+
+	// changing the case is not a change when comparing case insensitively
+	actual, conflicts := Merge3Func(base, ours, theirs, strings.EqualFold)
+	require.Nil(t, conflicts)
+	require.Equal(t, []string{"a", "b", "c", "d"}, actual)
+}
+
+func TestMerge3Resolve(t *testing.T) {
+	base := []int{1, 2, 3, 4, 5}
+	ours := []int{1, 20, 3, 4, 50}
+	theirs := []int{1, 21, 3, 4, 51}
+	eq := func(a, b int) bool { return a == b }
+
+	t.Run("all resolved", func(t *testing.T) {
+		var seen []Conflict[int]
+		actual, conflicts := Merge3Resolve(base, ours, theirs, eq, func(c Conflict[int]) ([]int, bool) {
+			seen = append(seen, c)
+			return Concat(c.Ours, c.Theirs), true
+		})
+		require.Nil(t, conflicts)
+		require.Equal(t, []int{1, 20, 21, 3, 4, 50, 51}, actual)
+		require.Len(t, seen, 2)
+	})
+
+	t.Run("partially resolved", func(t *testing.T) {
+		actual, conflicts := Merge3Resolve(base, ours, theirs, eq, func(c Conflict[int]) ([]int, bool) {
+			return c.Theirs, c.BaseStart == 1
+		})
+		require.Nil(t, actual)
+		require.Equal(t, []Conflict[int]{
+			{BaseStart: 4, BaseEnd: 5, Base: []int{5}, Ours: []int{50}, Theirs: []int{51}},
+		}, conflicts)
+	})
+}
+
+func TestDiff(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	eq := func(a, b int) bool { return a == b }
+	random := func() []int {
+		s := make([]int, r.Intn(12))
+		for i := range s {
+			s[i] = r.Intn(4)
+		}
+		return s
+	}
+
+	for i := 0; i < 1000; i++ {
+		base, other := random(), random()
+		hunks := diff(base, other, eq, false)
+
+		// applying the hunks turns base into other
+		var actual []int
+		cursor, edits := 0, 0
+		for _, h := range hunks {
+			require.Greater(t, h.end-h.start+len(h.values), 0)
+			require.GreaterOrEqual(t, h.start, cursor)
+			actual = append(actual, base[cursor:h.start]...)
+			actual = append(actual, h.values...)
+			cursor = h.end
+			edits += h.end - h.start + len(h.values)
+		}
+		actual = append(actual, base[cursor:]...)
+		require.True(t, slices.Equal(other, actual), "base %v other %v", base, other)
+
+		// the edits are minimal
+		lcs := make([][]int, len(base)+1)
+		for i := range lcs {
+			lcs[i] = make([]int, len(other)+1)
+		}
+		for i := len(base) - 1; i >= 0; i-- {
+			for j := len(other) - 1; j >= 0; j-- {
+				if base[i] == other[j] {
+					lcs[i][j] = lcs[i+1][j+1] + 1
+				} else {
+					lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+				}
+			}
+		}
+		require.Equal(t, len(base)+len(other)-2*lcs[0][0], edits, "base %v other %v", base, other)
+	}
+}
+
+func BenchmarkMerge3(b *testing.B) {
+	base := make([]int, 50000)
+	for i := range base {
+		base[i] = i
+	}
+	ours := Insert(Delete(base, 25000, 25010), 20000, -1, -2, -3)
+	theirs := Replace(base, 30000, 30005, -4)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		merged, conflicts := Merge3(base, ours, theirs)
+		if conflicts != nil || len(merged) != len(base)-10+3-5+1 {
+			b.Fatal("unexpected merge result")
+		}
+	}
+}
@@ -0,0 +1,60 @@
+// Package immutablestack provides a persistent LIFO stack. Pushing and popping return
+// new stacks in constant time while sharing all remaining elements with the original.
+package immutablestack
+
+import "github.com/mkeeler/go-immutable/immutablelist"
+
+// Stack is a persistent last-in first-out stack backed by an immutablelist.List. All
+// operations run in O(1) worst case time and never modify the receiver.
+//
+// The zero value of a Stack is an empty stack ready to use. A Stack is safe for
+// concurrent use by multiple goroutines without any additional locking.
+type Stack[E any] struct {
+	elems immutablelist.List[E]
+}
+
+// New creates a stack by pushing each of the specified elements in order. The last
+// element given will be on top of the stack.
+func New[E any](elems ...E) Stack[E] {
+	var s Stack[E]
+	for _, v := range elems {
+		s = s.Push(v)
+	}
+	return s
+}
+
+// Len returns the number of elements in the stack.
+func (s Stack[E]) Len() int {
+	return s.elems.Len()
+}
+
+// Push returns a new stack with v on top of the elements of s.
+func (s Stack[E]) Push(v E) Stack[E] {
+	return Stack[E]{elems: s.elems.Cons(v)}
+}
+
+// Peek returns the element on top of the stack. The boolean return value will be false
+// if the stack is empty.
+func (s Stack[E]) Peek() (E, bool) {
+	return s.elems.Head()
+}
+
+// Pop returns the element on top of the stack along with a new stack holding the
+// remaining elements. The boolean return value will be false if the stack is empty
+// in which case the returned stack is also empty.
+func (s Stack[E]) Pop() (E, Stack[E], bool) {
+	v, ok := s.elems.Head()
+	return v, Stack[E]{elems: s.elems.Tail()}, ok
+}
+
+// Range calls fn for each element of the stack starting at the top, which is the order
+// the elements would be popped in. Iteration stops early if fn returns false.
+func (s Stack[E]) Range(fn func(E) bool) {
+	s.elems.Range(fn)
+}
+
+// Slice returns a newly allocated slice holding the elements of the stack from top to
+// bottom. An empty stack results in a nil slice.
+func (s Stack[E]) Slice() []E {
+	return s.elems.Slice()
+}
@@ -0,0 +1,53 @@
+package immutablestack
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestZeroValue(t *testing.T) {
+	var s Stack[int]
+
+	require.Equal(t, 0, s.Len())
+	require.Nil(t, s.Slice())
+
+	_, ok := s.Peek()
+	require.False(t, ok)
+
+	_, popped, ok := s.Pop()
+	require.False(t, ok)
+	require.Equal(t, 0, popped.Len())
+}
+
+func TestPushPop(t *testing.T) {
+	s := New(1, 2, 3)
+	require.Equal(t, 3, s.Len())
+	require.Equal(t, []int{3, 2, 1}, s.Slice())
+
+	top, ok := s.Peek()
+	require.True(t, ok)
+	require.Equal(t, 3, top)
+
+	v, popped, ok := s.Pop()
+	require.True(t, ok)
+	require.Equal(t, 3, v)
+	require.Equal(t, []int{2, 1}, popped.Slice())
+
+	// the original stack is unaffected by the pop and by pushing onto the new version
+	pushed := popped.Push(42)
+	require.Equal(t, []int{42, 2, 1}, pushed.Slice())
+	require.Equal(t, []int{2, 1}, popped.Slice())
+	require.Equal(t, []int{3, 2, 1}, s.Slice())
+}
+
+func TestRange(t *testing.T) {
+	s := New(1, 2, 3, 4)
+
+	var firstTwo []int
+	s.Range(func(v int) bool {
+		firstTwo = append(firstTwo, v)
+		return len(firstTwo) < 2
+	})
+	require.Equal(t, []int{4, 3}, firstTwo)
+}
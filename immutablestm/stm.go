@@ -0,0 +1,194 @@
+// Package immutablestm provides software transactional memory for immutable values.
+// Transactions read and write any number of references and either commit all of their
+// writes at once or none of them. Because the values held by references are immutable,
+// a transaction never has to undo anything: aborting simply discards the new values it
+// built.
+package immutablestm
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	// clock is the global version clock. It holds the version of the most recently
+	// committed transaction.
+	clock atomic.Uint64
+
+	// commitMu serializes committing transactions.
+	commitMu sync.Mutex
+)
+
+// conflict is the value transactions panic with to abort and retry when they read a
+// reference modified after the transaction started.
+type conflict struct{}
+
+// cell is a committed value of a reference along with the version of the transaction
+// that committed it.
+type cell[T any] struct {
+	value   T
+	version uint64
+}
+
+// ref is the type independent part of a Ref that is needed to commit a transaction.
+type ref interface {
+	version() uint64
+	commit(value any, version uint64)
+}
+
+// Ref is a transactional reference to a value. Values held by a Ref must be immutable,
+// such as the slices produced by the immutableslice package, as the same value may be
+// observed by many transactions and goroutines.
+//
+// The zero value of a Ref holds the zero value of T and is ready to use. A Ref must not
+// be copied after first use.
+type Ref[T any] struct {
+	cell atomic.Pointer[cell[T]]
+}
+
+// NewRef creates a reference holding v.
+func NewRef[T any](v T) *Ref[T] {
+	r := &Ref[T]{}
+	r.cell.Store(&cell[T]{value: v})
+	return r
+}
+
+// Load returns the most recently committed value of the reference outside of a
+// transaction.
+func (r *Ref[T]) Load() T {
+	return r.load().value
+}
+
+// Get returns the value of the reference within the transaction tx. This is either the
+// value most recently set by tx or the value as of the start of tx. If the reference was
+// modified by another transaction since tx started, tx is aborted and retried.
+func (r *Ref[T]) Get(tx *Tx) T {
+	tx.check()
+	if v, ok := tx.writes[r]; ok {
+		return v.(T)
+	}
+
+	c := r.load()
+	if c.version > tx.readVersion {
+		panic(conflict{})
+	}
+	tx.reads[r] = c.version
+	return c.value
+}
+
+// Set sets the value of the reference within the transaction tx. Other transactions
+// observe the value only once tx commits.
+func (r *Ref[T]) Set(tx *Tx, v T) {
+	tx.check()
+	tx.writes[r] = v
+}
+
+// Update sets the value of the reference within the transaction tx to the result of
+// calling fn with its current value, and returns the new value.
+func (r *Ref[T]) Update(tx *Tx, fn func(T) T) T {
+	v := fn(r.Get(tx))
+	r.Set(tx, v)
+	return v
+}
+
+func (r *Ref[T]) load() *cell[T] {
+	if c := r.cell.Load(); c != nil {
+		return c
+	}
+	return &cell[T]{}
+}
+
+func (r *Ref[T]) version() uint64 {
+	return r.load().version
+}
+
+func (r *Ref[T]) commit(value any, version uint64) {
+	r.cell.Store(&cell[T]{value: value.(T), version: version})
+}
+
+// Tx is a transaction. Transactions are only valid within the function passed to
+// Atomically and must not be used by multiple goroutines.
+type Tx struct {
+	readVersion uint64
+	reads       map[ref]uint64
+	writes      map[ref]any
+	done        bool
+}
+
+func (tx *Tx) check() {
+	if tx.done {
+		panic("immutablestm: transaction used outside of Atomically")
+	}
+}
+
+// Atomically runs fn within a transaction. All references read by fn observe a
+// consistent snapshot, and all values set by fn become visible to other transactions at
+// once when fn returns nil. If fn returns an error, the values it set are discarded and
+// the error is returned.
+//
+// When another transaction commits a change to a reference read by fn, fn is aborted
+// and run again, so fn may be called multiple times and should not have side effects
+// other than setting references.
+func Atomically(fn func(tx *Tx) error) error {
+	for {
+		tx := &Tx{
+			readVersion: clock.Load(),
+			reads:       make(map[ref]uint64),
+			writes:      make(map[ref]any),
+		}
+
+		committed, err := tx.run(fn)
+		tx.done = true
+		if committed || err != nil {
+			return err
+		}
+		runtime.Gosched()
+	}
+}
+
+// run calls fn and tries to commit the transaction. It returns false without an error
+// when the transaction conflicted with another one and needs to be retried.
+func (tx *Tx) run(fn func(tx *Tx) error) (committed bool, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if _, ok := r.(conflict); !ok {
+				panic(r)
+			}
+			committed, err = false, nil
+		}
+	}()
+
+	if err := fn(tx); err != nil {
+		return false, err
+	}
+	return tx.commit(), nil
+}
+
+// commit validates that no reference read by the transaction was modified since it was
+// read and then publishes all writes with a new version.
+func (tx *Tx) commit() bool {
+	if len(tx.writes) == 0 {
+		// every read already observed the snapshot as of the start of the transaction
+		return true
+	}
+
+	commitMu.Lock()
+	defer commitMu.Unlock()
+
+	for r, version := range tx.reads {
+		if r.version() != version {
+			return false
+		}
+	}
+
+	// The new values are stored before advancing the clock. Transactions started
+	// before the clock advances will see the new version as being newer than their
+	// snapshot and retry, so no transaction can observe only some of the writes.
+	version := clock.Load() + 1
+	for r, v := range tx.writes {
+		r.commit(v, version)
+	}
+	clock.Store(version)
+	return true
+}
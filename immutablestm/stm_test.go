@@ -0,0 +1,185 @@
+package immutablestm
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/mkeeler/go-immutable/immutableslice"
+	"github.com/stretchr/testify/require"
+)
+
+// move moves the element at index i of from to the end of to.
+func move(from, to *Ref[[]int], i int) func(tx *Tx) error {
+	return func(tx *Tx) error {
+		src := from.Get(tx)
+		if i >= len(src) {
+			return errors.New("index out of range")
+		}
+		to.Set(tx, immutableslice.Append(to.Get(tx), src[i]))
+		from.Set(tx, immutableslice.Delete(src, i, i+1))
+		return nil
+	}
+}
+
+func TestAtomically(t *testing.T) {
+	a := NewRef([]int{1, 2, 3})
+	b := NewRef([]int(nil))
+
+	require.NoError(t, Atomically(move(a, b, 1)))
+	require.Equal(t, []int{1, 3}, a.Load())
+	require.Equal(t, []int{2}, b.Load())
+
+	// reads within a transaction observe its own writes
+	require.NoError(t, Atomically(func(tx *Tx) error {
+		a.Set(tx, nil)
+		require.Nil(t, a.Get(tx))
+		require.Equal(t, []int{1, 3}, a.Load())
+
+		require.Equal(t, []int{2, 4}, b.Update(tx, func(s []int) []int {
+			return immutableslice.Append(s, 4)
+		}))
+		return nil
+	}))
+	require.Nil(t, a.Load())
+	require.Equal(t, []int{2, 4}, b.Load())
+}
+
+func TestAtomicallyError(t *testing.T) {
+	a := NewRef([]int{1})
+	b := NewRef([]int{2})
+
+	// the write to b is discarded when the transaction fails
+	err := Atomically(func(tx *Tx) error {
+		b.Set(tx, nil)
+		return move(a, b, 5)(tx)
+	})
+	require.EqualError(t, err, "index out of range")
+	require.Equal(t, []int{1}, a.Load())
+	require.Equal(t, []int{2}, b.Load())
+}
+
+func TestZeroRef(t *testing.T) {
+	var r Ref[string]
+	require.Equal(t, "", r.Load())
+
+	require.NoError(t, Atomically(func(tx *Tx) error {
+		r.Set(tx, r.Get(tx)+"a")
+		return nil
+	}))
+	require.Equal(t, "a", r.Load())
+}
+
+func TestTxOutsideAtomically(t *testing.T) {
+	r := NewRef(1)
+
+	var leaked *Tx
+	require.NoError(t, Atomically(func(tx *Tx) error {
+		leaked = tx
+		return nil
+	}))
+
+	require.Panics(t, func() { r.Get(leaked) })
+	require.Panics(t, func() { r.Set(leaked, 2) })
+}
+
+func TestPanicPropagates(t *testing.T) {
+	require.PanicsWithValue(t, "boom", func() {
+		_ = Atomically(func(tx *Tx) error {
+			panic("boom")
+		})
+	})
+}
+
+func TestRetryOnConflict(t *testing.T) {
+	r := NewRef(0)
+
+	calls := 0
+	require.NoError(t, Atomically(func(tx *Tx) error {
+		calls += 1
+		v := r.Get(tx)
+		if calls == 1 {
+			// a concurrent transaction modifies r after it was read
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				_ = Atomically(func(tx *Tx) error {
+					r.Set(tx, 10)
+					return nil
+				})
+			}()
+			<-done
+		}
+		r.Set(tx, v+1)
+		return nil
+	}))
+
+	require.Equal(t, 2, calls)
+	require.Equal(t, 11, r.Load())
+}
+
+func TestConcurrentMoves(t *testing.T) {
+	const (
+		workers = 8
+		moves   = 200
+		items   = 20
+	)
+
+	lists := []*Ref[[]int]{NewRef([]int(nil)), NewRef([]int(nil)), NewRef([]int(nil))}
+	initial := make([]int, items)
+	for i := range initial {
+		initial[i] = i
+	}
+	lists[0] = NewRef(initial)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < moves; i++ {
+				from, to := lists[(w+i)%3], lists[(w+i+1)%3]
+				_ = Atomically(func(tx *Tx) error {
+					if len(from.Get(tx)) == 0 {
+						return nil
+					}
+					return move(from, to, 0)(tx)
+				})
+			}
+		}(w)
+	}
+
+	// readers always observe every item exactly once across all lists
+	done := make(chan struct{})
+	var readerErr error
+	go func() {
+		defer close(done)
+		for i := 0; i < 200; i++ {
+			var all []int
+			_ = Atomically(func(tx *Tx) error {
+				all = nil
+				for _, l := range lists {
+					all = append(all, l.Get(tx)...)
+				}
+				return nil
+			})
+			slices.Sort(all)
+			if !slices.Equal(initial, all) {
+				readerErr = errors.New("observed an inconsistent snapshot")
+				return
+			}
+		}
+	}()
+
+	wg.Wait()
+	<-done
+	require.NoError(t, readerErr)
+
+	var all []int
+	for _, l := range lists {
+		all = append(all, l.Load()...)
+	}
+	slices.Sort(all)
+	require.Equal(t, initial, all)
+}
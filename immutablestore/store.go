@@ -0,0 +1,311 @@
+// Package immutablestore provides a multi-version store of keyed immutable slices.
+// Readers obtain snapshots which offer a consistent view of all keys as of a single
+// version while writers keep modifying the store.
+package immutablestore
+
+import (
+	"cmp"
+	"errors"
+	"slices"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	// ErrVersionCollected is returned by AsOf when the requested version is older than
+	// every version the store still retains.
+	ErrVersionCollected = errors.New("immutablestore: version was garbage collected")
+
+	// ErrFutureVersion is returned by AsOf when the requested version was not written yet.
+	ErrFutureVersion = errors.New("immutablestore: version does not exist yet")
+)
+
+type options struct {
+	retain uint64
+}
+
+// Option configures a Store.
+type Option func(*options)
+
+// WithRetainedVersions makes the store retain the n most recent versions before the
+// current one even when no snapshot references them, so that they remain available to
+// AsOf. By default only the current version and versions referenced by open snapshots
+// are retained.
+func WithRetainedVersions(n uint64) Option {
+	return func(o *options) {
+		o.retain = n
+	}
+}
+
+// entry is the value of a key as written by a single version.
+type entry[E any] struct {
+	version uint64
+	value   []E
+	deleted bool
+}
+
+// Store maps keys to immutable slices. Every write creates a new version of the store,
+// starting with version zero for the empty store, and the previous values of the keys
+// it changes are retained for as long as an open snapshot or the retention policy may
+// still need them. All older versions are garbage collected.
+//
+// Slices stored in the store and returned by its snapshots are shared and must not be
+// modified.
+//
+// Stores must be created with New. A Store is safe for concurrent use by multiple
+// goroutines.
+type Store[K cmp.Ordered, E any] struct {
+	mu      sync.Mutex
+	version uint64
+	chains  map[K][]entry[E]
+	// snapshots counts the open snapshots of each version.
+	snapshots map[uint64]int
+	// horizon is the oldest version the store is able to provide.
+	horizon uint64
+	opts    options
+}
+
+// New creates an empty store.
+func New[K cmp.Ordered, E any](opts ...Option) *Store[K, E] {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return &Store[K, E]{
+		chains:    make(map[K][]entry[E]),
+		snapshots: make(map[uint64]int),
+		opts:      o,
+	}
+}
+
+// Version returns the current version of the store.
+func (s *Store[K, E]) Version() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.version
+}
+
+// Put sets the value of the key k to v and returns the new version of the store. The
+// store keeps a reference to v, so v must not be modified afterwards.
+func (s *Store[K, E]) Put(k K, v []E) uint64 {
+	var b Batch[K, E]
+	b.Put(k, v)
+	return s.Apply(&b)
+}
+
+// Delete removes the key k and returns the new version of the store.
+func (s *Store[K, E]) Delete(k K) uint64 {
+	var b Batch[K, E]
+	b.Delete(k)
+	return s.Apply(&b)
+}
+
+// Apply performs all writes of the batch as a single new version and returns it. Later
+// writes of a key within the batch take precedence over earlier ones. An empty batch
+// does not create a new version and the current version is returned instead.
+func (s *Store[K, E]) Apply(b *Batch[K, E]) uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(b.writes) == 0 {
+		return s.version
+	}
+
+	s.version += 1
+	for _, w := range b.writes {
+		chain := s.chains[w.key]
+		if n := len(chain); n > 0 && chain[n-1].version == s.version {
+			chain = chain[:n-1]
+		}
+		chain = append(chain, entry[E]{version: s.version, value: w.value, deleted: w.deleted})
+		s.chains[w.key] = chain
+	}
+
+	s.advanceHorizon()
+	for _, w := range b.writes {
+		s.collect(w.key)
+	}
+	return s.version
+}
+
+// Snapshot returns a snapshot of the current version. The snapshot must be closed once
+// it is no longer needed so that the versions it references can be garbage collected.
+func (s *Store[K, E]) Snapshot() *Snapshot[K, E] {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.openSnapshot(s.version)
+}
+
+// AsOf returns a snapshot of the version v. ErrVersionCollected is returned if the
+// version was garbage collected and ErrFutureVersion if it does not exist yet. The
+// snapshot must be closed once it is no longer needed.
+func (s *Store[K, E]) AsOf(v uint64) (*Snapshot[K, E], error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case v > s.version:
+		return nil, ErrFutureVersion
+	case v < s.horizon:
+		return nil, ErrVersionCollected
+	}
+	return s.openSnapshot(v), nil
+}
+
+func (s *Store[K, E]) openSnapshot(v uint64) *Snapshot[K, E] {
+	s.snapshots[v] += 1
+	return &Snapshot[K, E]{store: s, version: v}
+}
+
+func (s *Store[K, E]) closeSnapshot(v uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.snapshots[v] -= 1
+	if s.snapshots[v] > 0 {
+		return
+	}
+	delete(s.snapshots, v)
+
+	old := s.horizon
+	s.advanceHorizon()
+	if s.horizon != old {
+		for k := range s.chains {
+			s.collect(k)
+		}
+	}
+}
+
+// advanceHorizon moves the horizon to the oldest version that is still referenced by
+// an open snapshot or the retention policy.
+func (s *Store[K, E]) advanceHorizon() {
+	horizon := uint64(0)
+	if s.version > s.opts.retain {
+		horizon = s.version - s.opts.retain
+	}
+	for v := range s.snapshots {
+		horizon = min(horizon, v)
+	}
+	s.horizon = max(s.horizon, horizon)
+}
+
+// collect discards the entries of the key k which are not visible at any version since
+// the horizon.
+func (s *Store[K, E]) collect(k K) {
+	chain := s.chains[k]
+	if len(chain) == 0 {
+		return
+	}
+
+	// keep the newest entry at or before the horizon as it is the value visible at
+	// the horizon, along with all newer entries
+	idx := sort.Search(len(chain), func(i int) bool { return chain[i].version > s.horizon })
+	if idx > 0 {
+		idx -= 1
+	}
+
+	if idx == len(chain)-1 && chain[idx].deleted && chain[idx].version <= s.horizon {
+		// the key is deleted at every version since the horizon
+		delete(s.chains, k)
+		return
+	}
+	if idx > 0 {
+		s.chains[k] = slices.Clone(chain[idx:])
+	}
+}
+
+// get returns the value of the key k as of version v.
+func (s *Store[K, E]) get(k K, v uint64) ([]E, bool) {
+	chain := s.chains[k]
+	idx := sort.Search(len(chain), func(i int) bool { return chain[i].version > v })
+	if idx == 0 || chain[idx-1].deleted {
+		return nil, false
+	}
+	return chain[idx-1].value, true
+}
+
+// Batch collects writes to be applied to a store as a single version. The zero value of
+// a Batch is an empty batch ready to use. A Batch is not safe for concurrent use.
+type Batch[K cmp.Ordered, E any] struct {
+	writes []write[K, E]
+}
+
+type write[K cmp.Ordered, E any] struct {
+	key     K
+	value   []E
+	deleted bool
+}
+
+// Put adds a write setting the value of the key k to v. The store keeps a reference to
+// v, so v must not be modified afterwards.
+func (b *Batch[K, E]) Put(k K, v []E) {
+	b.writes = append(b.writes, write[K, E]{key: k, value: v})
+}
+
+// Delete adds a write removing the key k.
+func (b *Batch[K, E]) Delete(k K) {
+	b.writes = append(b.writes, write[K, E]{key: k, deleted: true})
+}
+
+// Len returns the number of writes in the batch.
+func (b *Batch[K, E]) Len() int {
+	return len(b.writes)
+}
+
+// Snapshot is a read-only view of a store as of a single version. Snapshots are not
+// affected by later writes to the store. A Snapshot is safe for concurrent use by
+// multiple goroutines.
+type Snapshot[K cmp.Ordered, E any] struct {
+	store   *Store[K, E]
+	version uint64
+	closed  atomic.Bool
+}
+
+// Version returns the version of the store the snapshot provides a view of.
+func (sn *Snapshot[K, E]) Version() uint64 {
+	return sn.version
+}
+
+// Get returns the value of the key k. The boolean return value will be false if the key
+// was not present. The slice must not be modified. It panics if the snapshot was closed.
+func (sn *Snapshot[K, E]) Get(k K) ([]E, bool) {
+	sn.check()
+
+	sn.store.mu.Lock()
+	defer sn.store.mu.Unlock()
+	return sn.store.get(k, sn.version)
+}
+
+// Keys returns a newly allocated slice holding all keys present in the snapshot in
+// ascending order. It panics if the snapshot was closed.
+func (sn *Snapshot[K, E]) Keys() []K {
+	sn.check()
+
+	sn.store.mu.Lock()
+	defer sn.store.mu.Unlock()
+
+	var keys []K
+	for k := range sn.store.chains {
+		if _, ok := sn.store.get(k, sn.version); ok {
+			keys = append(keys, k)
+		}
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// Close releases the snapshot so that the versions it references can be garbage
+// collected. Closing a snapshot more than once has no effect.
+func (sn *Snapshot[K, E]) Close() {
+	if sn.closed.CompareAndSwap(false, true) {
+		sn.store.closeSnapshot(sn.version)
+	}
+}
+
+func (sn *Snapshot[K, E]) check() {
+	if sn.closed.Load() {
+		panic("immutablestore: snapshot used after Close")
+	}
+}
@@ -0,0 +1,191 @@
+package immutablestore
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/mkeeler/go-immutable/immutableslice"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshot(t *testing.T) {
+	s := New[string, int]()
+	require.Equal(t, uint64(0), s.Version())
+
+	require.Equal(t, uint64(1), s.Put("a", []int{1}))
+	require.Equal(t, uint64(2), s.Put("b", []int{2}))
+
+	snap := s.Snapshot()
+	defer snap.Close()
+	require.Equal(t, uint64(2), snap.Version())
+
+	s.Put("a", []int{1, 1})
+	s.Delete("b")
+	s.Put("c", []int{3})
+
+	// the snapshot is not affected by later writes
+	a, ok := snap.Get("a")
+	require.True(t, ok)
+	require.Equal(t, []int{1}, a)
+	b, ok := snap.Get("b")
+	require.True(t, ok)
+	require.Equal(t, []int{2}, b)
+	_, ok = snap.Get("c")
+	require.False(t, ok)
+	require.Equal(t, []string{"a", "b"}, snap.Keys())
+
+	latest := s.Snapshot()
+	defer latest.Close()
+	a, _ = latest.Get("a")
+	require.Equal(t, []int{1, 1}, a)
+	_, ok = latest.Get("b")
+	require.False(t, ok)
+	require.Equal(t, []string{"a", "c"}, latest.Keys())
+}
+
+func TestApplyBatch(t *testing.T) {
+	s := New[string, string]()
+	s.Put("moved", []string{"x"})
+
+	var b Batch[string, string]
+	b.Delete("moved")
+	b.Put("target", []string{"x"})
+	b.Put("other", []string{"y"})
+	b.Put("other", []string{"z"})
+	require.Equal(t, 4, b.Len())
+
+	before := s.Snapshot()
+	defer before.Close()
+
+	require.Equal(t, uint64(2), s.Apply(&b))
+	require.Equal(t, uint64(2), s.Apply(&Batch[string, string]{}))
+
+	after := s.Snapshot()
+	defer after.Close()
+	require.Equal(t, []string{"moved"}, before.Keys())
+	require.Equal(t, []string{"other", "target"}, after.Keys())
+
+	other, _ := after.Get("other")
+	require.Equal(t, []string{"z"}, other)
+}
+
+func TestAsOf(t *testing.T) {
+	s := New[string, int](WithRetainedVersions(2))
+
+	for i := 1; i <= 5; i++ {
+		s.Put("k", []int{i})
+	}
+
+	type testCase struct {
+		version  uint64
+		expected []int
+		err      error
+	}
+
+	cases := map[string]testCase{
+		"current": {
+			version:  5,
+			expected: []int{5},
+		},
+		"retained": {
+			version:  3,
+			expected: []int{3},
+		},
+		"collected": {
+			version: 2,
+			err:     ErrVersionCollected,
+		},
+		"future": {
+			version: 6,
+			err:     ErrFutureVersion,
+		},
+	}
+
+	for name, tcase := range cases {
+		tcase := tcase
+
+		t.Run(name, func(t *testing.T) {
+			snap, err := s.AsOf(tcase.version)
+			if tcase.err != nil {
+				require.ErrorIs(t, err, tcase.err)
+				require.Nil(t, snap)
+				return
+			}
+
+			require.NoError(t, err)
+			defer snap.Close()
+			actual, ok := snap.Get("k")
+			require.True(t, ok)
+			require.Equal(t, tcase.expected, actual)
+		})
+	}
+}
+
+func TestGarbageCollection(t *testing.T) {
+	s := New[string, int]()
+	s.Put("a", []int{1})
+	s.Put("b", []int{1})
+
+	snap := s.Snapshot()
+	s.Put("a", []int{2})
+	s.Put("a", []int{3})
+	s.Delete("b")
+
+	// versions newer than the open snapshot remain available
+	mid, err := s.AsOf(3)
+	require.NoError(t, err)
+	a, _ := mid.Get("a")
+	require.Equal(t, []int{2}, a)
+	mid.Close()
+
+	require.Len(t, s.chains["a"], 3)
+	require.Len(t, s.chains["b"], 2)
+
+	// closing the last snapshot collects every version but the current one
+	snap.Close()
+	snap.Close()
+	require.Len(t, s.chains["a"], 1)
+	require.NotContains(t, s.chains, "b")
+
+	_, err = s.AsOf(4)
+	require.ErrorIs(t, err, ErrVersionCollected)
+	require.Panics(t, func() { snap.Get("a") })
+	require.Panics(t, func() { snap.Keys() })
+
+	// writes without open snapshots only keep the current value
+	s.Put("a", []int{4})
+	require.Len(t, s.chains["a"], 1)
+}
+
+func TestConcurrentSnapshots(t *testing.T) {
+	s := New[int, int]()
+	s.Put(0, nil)
+	s.Put(1, nil)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		// move values from one key to the other, keeping the total constant
+		for i := 0; i < 500; i++ {
+			snap := s.Snapshot()
+			from, _ := snap.Get(i % 2)
+			to, _ := snap.Get((i + 1) % 2)
+			snap.Close()
+
+			var b Batch[int, int]
+			b.Put(i%2, immutableslice.Append(from, i))
+			b.Put((i+1)%2, to)
+			s.Apply(&b)
+		}
+	}()
+
+	for i := 0; i < 500; i++ {
+		snap := s.Snapshot()
+		first, _ := snap.Get(0)
+		second, _ := snap.Get(1)
+		require.Equal(t, int(snap.Version())-2, len(first)+len(second))
+		snap.Close()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,238 @@
+// Package immutabletest provides test helpers for verifying that functions operating on
+// slices honor the same contract as the immutableslice package: inputs are never
+// modified and outputs never share a backing array with the inputs.
+package immutabletest
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"sort"
+	"strings"
+	"unsafe"
+)
+
+// TestingT is the subset of testing.TB used by the assertions of this package. It is
+// satisfied by *testing.T, *testing.B and *testing.F.
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...any)
+}
+
+// snapshot is a copy of an input taken before the function under test runs.
+type snapshot struct {
+	value reflect.Value
+	// full is a copy of every element up to the capacity of a slice, or the copy of the
+	// value pointed to by a pointer.
+	full reflect.Value
+	// entries is a copy of a map.
+	entries reflect.Value
+}
+
+// AssertNoMutation calls fn and reports an error for each input which fn modified. The
+// inputs may be slices, maps and pointers. Slices are checked up to their capacity, so
+// writing to the spare capacity of a slice, e.g. by appending to it, is detected as
+// well. Pointers are checked by comparing the values they point to.
+//
+// Elements are compared with reflect.DeepEqual against a shallow copy taken before
+// calling fn, so modifications made through pointers held by the elements are not
+// detected. It returns whether no mutation was found.
+func AssertNoMutation(t TestingT, fn func(), inputs ...any) bool {
+	t.Helper()
+
+	snapshots := make([]snapshot, len(inputs))
+	for i, in := range inputs {
+		snapshots[i] = take(reflect.ValueOf(in))
+	}
+
+	fn()
+
+	ok := true
+	for i, snap := range snapshots {
+		if diff := snap.diff(); diff != "" {
+			t.Errorf("immutabletest: input %d (%s) was modified:\n%s", i, snap.value.Type(), diff)
+			ok = false
+		}
+	}
+	return ok
+}
+
+func take(v reflect.Value) snapshot {
+	snap := snapshot{value: v}
+	switch v.Kind() {
+	case reflect.Slice:
+		full := reflect.MakeSlice(v.Type(), v.Cap(), v.Cap())
+		reflect.Copy(full, v.Slice(0, v.Cap()))
+		snap.full = full
+	case reflect.Map:
+		if !v.IsNil() {
+			entries := reflect.MakeMapWithSize(v.Type(), v.Len())
+			iter := v.MapRange()
+			for iter.Next() {
+				entries.SetMapIndex(iter.Key(), iter.Value())
+			}
+			snap.entries = entries
+		}
+	case reflect.Pointer:
+		if !v.IsNil() {
+			full := reflect.New(v.Elem().Type()).Elem()
+			full.Set(v.Elem())
+			snap.full = full
+		}
+	default:
+		panic(fmt.Sprintf("immutabletest: unsupported input of type %s, inputs must be slices, maps or pointers", v.Type()))
+	}
+	return snap
+}
+
+// diff describes how the input changed since the snapshot was taken.
+func (s snapshot) diff() string {
+	var b strings.Builder
+	switch s.value.Kind() {
+	case reflect.Slice:
+		current := s.value.Slice(0, s.value.Cap())
+		for i := 0; i < current.Len(); i++ {
+			before, after := s.full.Index(i).Interface(), current.Index(i).Interface()
+			if reflect.DeepEqual(before, after) {
+				continue
+			}
+			where := "index"
+			if i >= s.value.Len() {
+				where = "spare capacity index"
+			}
+			fmt.Fprintf(&b, "  %s %d: %#v => %#v\n", where, i, before, after)
+		}
+	case reflect.Map:
+		if !s.entries.IsValid() {
+			return ""
+		}
+		// map iteration order is random, so the changes are sorted to be reproducible
+		var changes []string
+		for _, k := range s.entries.MapKeys() {
+			before, after := s.entries.MapIndex(k), s.value.MapIndex(k)
+			switch {
+			case !after.IsValid():
+				changes = append(changes, fmt.Sprintf("  key %#v: deleted\n", k.Interface()))
+			case !reflect.DeepEqual(before.Interface(), after.Interface()):
+				changes = append(changes, fmt.Sprintf("  key %#v: %#v => %#v\n", k.Interface(), before.Interface(), after.Interface()))
+			}
+		}
+		for _, k := range s.value.MapKeys() {
+			if !s.entries.MapIndex(k).IsValid() {
+				changes = append(changes, fmt.Sprintf("  key %#v: added %#v\n", k.Interface(), s.value.MapIndex(k).Interface()))
+			}
+		}
+		sort.Strings(changes)
+		b.WriteString(strings.Join(changes, ""))
+	case reflect.Pointer:
+		if s.full.IsValid() && !reflect.DeepEqual(s.full.Interface(), s.value.Elem().Interface()) {
+			fmt.Fprintf(&b, "  %#v => %#v\n", s.full.Interface(), s.value.Elem().Interface())
+		}
+	}
+	return b.String()
+}
+
+// AssertNoAlias reports an error if the backing arrays of in and out overlap, which
+// means that modifying out could modify in or the other way around. Slices with a
+// capacity of zero never alias. It returns whether the slices do not alias.
+func AssertNoAlias[S ~[]E, E any](t TestingT, in, out S) bool {
+	t.Helper()
+
+	var zero E
+	size := unsafe.Sizeof(zero)
+	if cap(in) == 0 || cap(out) == 0 || size == 0 {
+		return true
+	}
+
+	inStart := uintptr(unsafe.Pointer(unsafe.SliceData([]E(in))))
+	inEnd := inStart + uintptr(cap(in))*size
+	outStart := uintptr(unsafe.Pointer(unsafe.SliceData([]E(out))))
+	outEnd := outStart + uintptr(cap(out))*size
+
+	if inStart < outEnd && outStart < inEnd {
+		t.Errorf("immutabletest: output shares its backing array with the input: input elements [%d:%d] overlap output elements [%d:%d]",
+			(max(inStart, outStart)-inStart)/size, (min(inEnd, outEnd)-inStart)/size,
+			(max(inStart, outStart)-outStart)/size, (min(inEnd, outEnd)-outStart)/size)
+		return false
+	}
+	return true
+}
+
+// AssertEqual reports an error listing every differing element if want and got are not
+// equal according to reflect.DeepEqual. It returns whether the slices are equal.
+func AssertEqual[S ~[]E, E any](t TestingT, want, got S) bool {
+	t.Helper()
+
+	if diff := Diff(want, got); diff != "" {
+		t.Errorf("immutabletest: slices differ:\n%s", diff)
+		return false
+	}
+	return true
+}
+
+// Diff returns a readable description of the element level differences between want
+// and got, or an empty string if they are equal according to reflect.DeepEqual. A nil
+// slice and an empty slice are considered equal.
+func Diff[S ~[]E, E any](want, got S) string {
+	var b strings.Builder
+	if len(want) != len(got) {
+		fmt.Fprintf(&b, "  length: %d => %d\n", len(want), len(got))
+	}
+	for i := 0; i < max(len(want), len(got)); i++ {
+		switch {
+		case i >= len(got):
+			fmt.Fprintf(&b, "  index %d: %#v => missing\n", i, want[i])
+		case i >= len(want):
+			fmt.Fprintf(&b, "  index %d: extra %#v\n", i, got[i])
+		case !reflect.DeepEqual(want[i], got[i]):
+			fmt.Fprintf(&b, "  index %d: %#v => %#v\n", i, want[i], got[i])
+		}
+	}
+	return b.String()
+}
+
+// RandomSlice returns a slice of up to maxLen elements produced by gen. The slice is
+// given a random amount of spare capacity, filled with elements from gen as well, so
+// that functions writing beyond the length of their inputs can be detected. A slice of
+// length zero is nil half of the time.
+func RandomSlice[E any](r *rand.Rand, maxLen int, gen func(*rand.Rand) E) []E {
+	n := r.Intn(maxLen + 1)
+	if n == 0 && r.Intn(2) == 0 {
+		return nil
+	}
+
+	s := make([]E, n+r.Intn(maxLen+1))
+	for i := range s {
+		s[i] = gen(r)
+	}
+	return s[:n]
+}
+
+// RandomRange returns random indexes i and j such that s[i:j] is a valid slice of a
+// slice s of length n.
+func RandomRange(r *rand.Rand, n int) (i, j int) {
+	i = r.Intn(n + 1)
+	j = i + r.Intn(n-i+1)
+	return i, j
+}
+
+// Ints returns a generator for RandomSlice producing integers in the range [0, n).
+// Using a small n produces duplicate elements which is useful for testing functions
+// such as Compact.
+func Ints(n int) func(*rand.Rand) int {
+	return func(r *rand.Rand) int {
+		return r.Intn(n)
+	}
+}
+
+// Strings returns a generator for RandomSlice producing strings of up to maxLen lower
+// case ASCII letters.
+func Strings(maxLen int) func(*rand.Rand) string {
+	return func(r *rand.Rand) string {
+		b := make([]byte, r.Intn(maxLen+1))
+		for i := range b {
+			b[i] = byte('a' + r.Intn(26))
+		}
+		return string(b)
+	}
+}
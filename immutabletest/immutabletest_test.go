@@ -0,0 +1,204 @@
+package immutabletest
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/mkeeler/go-immutable/immutableslice"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeT records the errors reported by the assertions.
+type fakeT struct {
+	errors []string
+}
+
+func (f *fakeT) Helper() {}
+
+func (f *fakeT) Errorf(format string, args ...any) {
+	f.errors = append(f.errors, fmt.Sprintf(format, args...))
+}
+
+func TestAssertNoMutation(t *testing.T) {
+	type testCase struct {
+		fn       func(s []int, m map[string]int, p *int)
+		expected []string
+	}
+
+	cases := map[string]testCase{
+		"no mutation": {
+			fn: func(s []int, m map[string]int, p *int) {
+				_ = immutableslice.Insert(s, 1, 10)
+				_ = immutableslice.Delete(s, 0, 1)
+			},
+		},
+		"element changed": {
+			fn: func(s []int, m map[string]int, p *int) { s[1] = 10 },
+			expected: []string{
+				"immutabletest: input 0 ([]int) was modified:\n  index 1: 2 => 10\n",
+			},
+		},
+		"spare capacity changed": {
+			fn: func(s []int, m map[string]int, p *int) { _ = append(s, 10) },
+			expected: []string{
+				"immutabletest: input 0 ([]int) was modified:\n  spare capacity index 3: 4 => 10\n",
+			},
+		},
+		"map changed": {
+			fn: func(s []int, m map[string]int, p *int) {
+				m["a"] = 10
+				delete(m, "b")
+				m["c"] = 3
+			},
+			expected: []string{
+				"immutabletest: input 1 (map[string]int) was modified:\n" +
+					"  key \"a\": 1 => 10\n" +
+					"  key \"b\": deleted\n" +
+					"  key \"c\": added 3\n",
+			},
+		},
+		"pointer changed": {
+			fn: func(s []int, m map[string]int, p *int) { *p = 10 },
+			expected: []string{
+				"immutabletest: input 2 (*int) was modified:\n  1 => 10\n",
+			},
+		},
+	}
+
+	for name, tcase := range cases {
+		tcase := tcase
+
+		t.Run(name, func(t *testing.T) {
+			s := []int{1, 2, 3, 4}[:3]
+			m := map[string]int{"a": 1, "b": 2}
+			p := new(int)
+			*p = 1
+
+			ft := &fakeT{}
+			ok := AssertNoMutation(ft, func() { tcase.fn(s, m, p) }, s, m, p)
+			require.Equal(t, tcase.expected, ft.errors)
+			require.Equal(t, len(tcase.expected) == 0, ok)
+		})
+	}
+
+	require.Panics(t, func() { AssertNoMutation(&fakeT{}, func() {}, 1) })
+}
+
+func TestAssertNoAlias(t *testing.T) {
+	s := []int{1, 2, 3, 4, 5}
+
+	type testCase struct {
+		in       []int
+		out      []int
+		expected []string
+	}
+
+	cases := map[string]testCase{
+		"fresh array": {
+			in:  s,
+			out: immutableslice.Insert(s, 0, 0),
+		},
+		"same slice": {
+			in:  s,
+			out: s,
+			expected: []string{
+				"immutabletest: output shares its backing array with the input: input elements [0:5] overlap output elements [0:5]",
+			},
+		},
+		"subslice": {
+			in:  s,
+			out: s[3:4],
+			expected: []string{
+				"immutabletest: output shares its backing array with the input: input elements [3:5] overlap output elements [0:2]",
+			},
+		},
+		"spare capacity": {
+			in:  s[:2],
+			out: s[4:],
+			expected: []string{
+				"immutabletest: output shares its backing array with the input: input elements [4:5] overlap output elements [0:1]",
+			},
+		},
+		"disjoint parts": {
+			in:  s[:2:2],
+			out: s[2:],
+		},
+		"nil output": {
+			in:  s,
+			out: nil,
+		},
+	}
+
+	for name, tcase := range cases {
+		tcase := tcase
+
+		t.Run(name, func(t *testing.T) {
+			ft := &fakeT{}
+			ok := AssertNoAlias(ft, tcase.in, tcase.out)
+			require.Equal(t, tcase.expected, ft.errors)
+			require.Equal(t, len(tcase.expected) == 0, ok)
+		})
+	}
+}
+
+func TestDiff(t *testing.T) {
+	require.Empty(t, Diff([]int(nil), []int{}))
+	require.Empty(t, Diff([]int{1, 2}, []int{1, 2}))
+	require.Equal(t, "  index 1: 2 => 3\n", Diff([]int{1, 2}, []int{1, 3}))
+	require.Equal(t, "  length: 1 => 3\n  index 1: extra \"b\"\n  index 2: extra \"c\"\n", Diff([]string{"a"}, []string{"a", "b", "c"}))
+	require.Equal(t, "  length: 2 => 0\n  index 0: 1 => missing\n  index 1: 2 => missing\n", Diff([]int{1, 2}, nil))
+
+	ft := &fakeT{}
+	require.False(t, AssertEqual(ft, []int{1, 2}, []int{1, 3}))
+	require.Equal(t, []string{"immutabletest: slices differ:\n  index 1: 2 => 3\n"}, ft.errors)
+	require.True(t, AssertEqual(ft, []int{1}, []int{1}))
+}
+
+func TestGenerators(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	sawNil, sawSpare := false, false
+	for i := 0; i < 100; i++ {
+		s := RandomSlice(r, 8, Ints(3))
+		require.LessOrEqual(t, len(s), 8)
+		for _, v := range s[:cap(s)] {
+			require.GreaterOrEqual(t, v, 0)
+			require.Less(t, v, 3)
+		}
+		sawNil = sawNil || s == nil
+		sawSpare = sawSpare || cap(s) > len(s)
+
+		i, j := RandomRange(r, len(s))
+		require.LessOrEqual(t, 0, i)
+		require.LessOrEqual(t, i, j)
+		require.LessOrEqual(t, j, len(s))
+
+		for _, str := range RandomSlice(r, 4, Strings(5)) {
+			require.LessOrEqual(t, len(str), 5)
+		}
+	}
+	require.True(t, sawNil)
+	require.True(t, sawSpare)
+}
+
+// TestImmutableSliceContract shows how the helpers are meant to be used: checking
+// random inputs against the contract of the immutableslice package.
+func TestImmutableSliceContract(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	for n := 0; n < 200; n++ {
+		s := RandomSlice(r, 10, Ints(5))
+		i, j := RandomRange(r, len(s))
+
+		var out []int
+		AssertNoMutation(t, func() { out = immutableslice.Delete(s, i, j) }, s)
+		AssertNoAlias(t, s, out)
+		AssertEqual(t, slices.Delete(slices.Clone(s), i, j), out)
+
+		AssertNoMutation(t, func() { out = immutableslice.Compact(s) }, s)
+		AssertNoAlias(t, s, out)
+		AssertEqual(t, slices.Compact(slices.Clone(s)), out)
+	}
+}
@@ -0,0 +1,266 @@
+// Package analysistest runs the analyzers of this module on the packages of a testdata
+// directory and checks their diagnostics and suggested fixes.
+//
+// The analysistest package of the x/tools versions supporting Go 1.21 does not load
+// packages with newer versions of Go, so this package loads and type checks the
+// packages itself. The testdata directory is laid out and expectations are written
+// like those of analysistest: the package with import path p lives in dir/src/p, and a
+// comment of the form // want `regexp` must match a diagnostic reported on its line.
+// Packages which are not found in the testdata directory are imported from the
+// standard library.
+package analysistest
+
+import (
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/tools/go/analysis"
+
+	"github.com/mkeeler/go-immutable/internal/textedit"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+// Result is a package of the testdata directory which has been analyzed.
+type Result struct {
+	Files       []*ast.File
+	Pkg         *types.Package
+	Diagnostics []analysis.Diagnostic
+}
+
+// Run analyzes the packages of dir/src with the given import paths, along with the
+// packages of dir/src they import, and checks the diagnostics against the want comments
+// of their files.
+func Run(t *testing.T, dir string, a *analysis.Analyzer, paths ...string) map[string]*Result {
+	t.Helper()
+
+	_, results := run(t, dir, a, paths)
+	return results
+}
+
+// RunWithSuggestedFixes is like Run, and additionally applies the suggested fixes of all
+// diagnostics to the files of the packages. The formatted result must match the golden
+// file named like the file with a .golden suffix. Run the tests with -update to write
+// the golden files.
+func RunWithSuggestedFixes(t *testing.T, dir string, a *analysis.Analyzer, paths ...string) map[string]*Result {
+	t.Helper()
+
+	fset, results := run(t, dir, a, paths)
+	for _, path := range paths {
+		result, ok := results[path]
+		if !ok {
+			continue
+		}
+
+		var edits []analysis.TextEdit
+		for _, d := range result.Diagnostics {
+			for _, fix := range d.SuggestedFixes {
+				edits = append(edits, fix.TextEdits...)
+			}
+		}
+		byFile := textedit.ByFile(fset, edits)
+
+		for _, file := range result.Files {
+			filename := fset.File(file.Pos()).Name()
+			golden := filename + ".golden"
+			if _, err := os.Stat(golden); err != nil && len(byFile[filename]) == 0 && !*update {
+				// files without fixes need no golden file
+				continue
+			}
+
+			src, err := os.ReadFile(filename)
+			require.NoError(t, err)
+			fixed, err := textedit.Apply(src, byFile[filename])
+			require.NoError(t, err, filename)
+			fixed, err = format.Source(fixed)
+			require.NoError(t, err, filename)
+
+			if *update {
+				require.NoError(t, os.WriteFile(golden, fixed, 0o644))
+			}
+			expected, err := os.ReadFile(golden)
+			require.NoError(t, err)
+			require.Equal(t, string(expected), string(fixed), golden)
+		}
+	}
+	return results
+}
+
+func run(t *testing.T, dir string, a *analysis.Analyzer, paths []string) (*token.FileSet, map[string]*Result) {
+	t.Helper()
+
+	l := &loader{
+		analyzer: a,
+		root:     filepath.Join(dir, "src"),
+		fset:     token.NewFileSet(),
+		packages: make(map[string]*Result),
+		facts:    make(map[types.Object]analysis.Fact),
+	}
+	l.std = importer.ForCompiler(l.fset, "source", nil)
+
+	results := make(map[string]*Result)
+	for _, path := range paths {
+		path := path
+
+		t.Run(path, func(t *testing.T) {
+			result, err := l.load(path)
+			require.NoError(t, err)
+			checkExpectations(t, l.fset, result)
+			results[path] = result
+		})
+	}
+	return l.fset, results
+}
+
+// loader type checks and analyzes the packages of the testdata directory, along with
+// the packages of the testdata directory which they import.
+type loader struct {
+	analyzer *analysis.Analyzer
+	root     string
+	fset     *token.FileSet
+	std      types.Importer
+	packages map[string]*Result
+	facts    map[types.Object]analysis.Fact
+}
+
+// Import implements types.Importer, importing packages from the testdata directory
+// before the standard library.
+func (l *loader) Import(path string) (*types.Package, error) {
+	if _, err := os.Stat(filepath.Join(l.root, path)); err != nil {
+		return l.std.Import(path)
+	}
+	result, err := l.load(path)
+	if err != nil {
+		return nil, err
+	}
+	return result.Pkg, nil
+}
+
+func (l *loader) load(path string) (*Result, error) {
+	if result, ok := l.packages[path]; ok {
+		return result, nil
+	}
+
+	dir := filepath.Join(l.root, path)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{}
+	for _, entry := range entries {
+		if !strings.HasSuffix(entry.Name(), ".go") {
+			continue
+		}
+		file, err := parser.ParseFile(l.fset, filepath.Join(dir, entry.Name()), nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		result.Files = append(result.Files, file)
+	}
+
+	info := &types.Info{
+		Types:      make(map[ast.Expr]types.TypeAndValue),
+		Defs:       make(map[*ast.Ident]types.Object),
+		Uses:       make(map[*ast.Ident]types.Object),
+		Implicits:  make(map[ast.Node]types.Object),
+		Selections: make(map[*ast.SelectorExpr]*types.Selection),
+		Scopes:     make(map[ast.Node]*types.Scope),
+		Instances:  make(map[*ast.Ident]types.Instance),
+	}
+	conf := types.Config{Importer: l}
+	result.Pkg, err = conf.Check(path, l.fset, result.Files, info)
+	if err != nil {
+		return nil, err
+	}
+
+	pass := &analysis.Pass{
+		Analyzer:   l.analyzer,
+		Fset:       l.fset,
+		Files:      result.Files,
+		Pkg:        result.Pkg,
+		TypesInfo:  info,
+		TypesSizes: types.SizesFor("gc", "amd64"),
+		ResultOf:   make(map[*analysis.Analyzer]any),
+		ReadFile:   os.ReadFile,
+		Report: func(d analysis.Diagnostic) {
+			result.Diagnostics = append(result.Diagnostics, d)
+		},
+		ImportObjectFact: func(obj types.Object, fact analysis.Fact) bool {
+			stored, ok := l.facts[obj]
+			if ok {
+				reflect.ValueOf(fact).Elem().Set(reflect.ValueOf(stored).Elem())
+			}
+			return ok
+		},
+		ExportObjectFact: func(obj types.Object, fact analysis.Fact) {
+			l.facts[obj] = fact
+		},
+	}
+	if _, err := l.analyzer.Run(pass); err != nil {
+		return nil, err
+	}
+
+	l.packages[path] = result
+	return result, nil
+}
+
+var wantRe = regexp.MustCompile("// want `([^`]*)`")
+
+// checkExpectations matches the diagnostics reported for the package against the want
+// comments of its files.
+func checkExpectations(t *testing.T, fset *token.FileSet, result *Result) {
+	t.Helper()
+
+	want := make(map[string][]*regexp.Regexp)
+	for _, file := range result.Files {
+		for _, group := range file.Comments {
+			for _, comment := range group.List {
+				m := wantRe.FindStringSubmatch(comment.Text)
+				if m == nil {
+					continue
+				}
+				key := lineKey(fset.Position(comment.Pos()))
+				want[key] = append(want[key], regexp.MustCompile(m[1]))
+			}
+		}
+	}
+
+	for _, d := range result.Diagnostics {
+		key := lineKey(fset.Position(d.Pos))
+		matched := false
+		for i, re := range want[key] {
+			if re.MatchString(d.Message) {
+				want[key] = append(want[key][:i:i], want[key][i+1:]...)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			t.Errorf("%s: unexpected diagnostic: %s", key, d.Message)
+		}
+	}
+
+	for key, res := range want {
+		for _, re := range res {
+			t.Errorf("%s: no diagnostic matching %s", key, re)
+		}
+	}
+}
+
+func lineKey(pos token.Position) string {
+	return fmt.Sprintf("%s:%d", filepath.Base(pos.Filename), pos.Line)
+}
@@ -0,0 +1,184 @@
+// Package ordmap provides a persistent ordered map used as a building block by the
+// persistent collections of this module.
+package ordmap
+
+// node is a node of an AVL tree. Nodes are never modified after they are created which
+// allows them to be shared between maps.
+type node[K, V any] struct {
+	key    K
+	value  V
+	left   *node[K, V]
+	right  *node[K, V]
+	height int
+}
+
+func (n *node[K, V]) getHeight() int {
+	if n == nil {
+		return 0
+	}
+	return n.height
+}
+
+func newNode[K, V any](key K, value V, left, right *node[K, V]) *node[K, V] {
+	return &node[K, V]{
+		key:    key,
+		value:  value,
+		left:   left,
+		right:  right,
+		height: max(left.getHeight(), right.getHeight()) + 1,
+	}
+}
+
+// Map is a persistent map ordered by a comparison function and implemented as an AVL
+// tree. Set and Delete run in O(log n) time and only copy the nodes along the path to
+// the modified key.
+//
+// Maps must be created with New. A Map is safe for concurrent use by multiple goroutines
+// as none of its methods modify it.
+type Map[K, V any] struct {
+	root *node[K, V]
+	size int
+	cmp  func(a, b K) int
+}
+
+// New creates an empty map ordered by the cmp function.
+func New[K, V any](cmp func(a, b K) int) Map[K, V] {
+	return Map[K, V]{cmp: cmp}
+}
+
+// Len returns the number of entries in the map.
+func (m Map[K, V]) Len() int {
+	return m.size
+}
+
+// Get returns the value stored for the key along with whether it was found.
+func (m Map[K, V]) Get(key K) (V, bool) {
+	n := m.root
+	for n != nil {
+		switch c := m.cmp(key, n.key); {
+		case c < 0:
+			n = n.left
+		case c > 0:
+			n = n.right
+		default:
+			return n.value, true
+		}
+	}
+
+	var zero V
+	return zero, false
+}
+
+// Set returns a new map with the key set to the value.
+func (m Map[K, V]) Set(key K, value V) Map[K, V] {
+	root, added := m.set(m.root, key, value)
+	m.root = root
+	if added {
+		m.size += 1
+	}
+	return m
+}
+
+// Delete returns a new map without the key. If the key is not present, the returned
+// map is identical to m.
+func (m Map[K, V]) Delete(key K) Map[K, V] {
+	root, deleted := m.delete(m.root, key)
+	if deleted {
+		m.root = root
+		m.size -= 1
+	}
+	return m
+}
+
+// Range calls fn for each entry of the map in key order. Iteration stops early if fn
+// returns false.
+func (m Map[K, V]) Range(fn func(K, V) bool) {
+	walk(m.root, fn)
+}
+
+func walk[K, V any](n *node[K, V], fn func(K, V) bool) bool {
+	if n == nil {
+		return true
+	}
+	return walk(n.left, fn) && fn(n.key, n.value) && walk(n.right, fn)
+}
+
+func (m Map[K, V]) set(n *node[K, V], key K, value V) (*node[K, V], bool) {
+	if n == nil {
+		return newNode(key, value, nil, nil), true
+	}
+
+	switch c := m.cmp(key, n.key); {
+	case c < 0:
+		left, added := m.set(n.left, key, value)
+		return balance(n.key, n.value, left, n.right), added
+	case c > 0:
+		right, added := m.set(n.right, key, value)
+		return balance(n.key, n.value, n.left, right), added
+	default:
+		return newNode(key, value, n.left, n.right), false
+	}
+}
+
+func (m Map[K, V]) delete(n *node[K, V], key K) (*node[K, V], bool) {
+	if n == nil {
+		return nil, false
+	}
+
+	switch c := m.cmp(key, n.key); {
+	case c < 0:
+		left, deleted := m.delete(n.left, key)
+		if !deleted {
+			return n, false
+		}
+		return balance(n.key, n.value, left, n.right), true
+	case c > 0:
+		right, deleted := m.delete(n.right, key)
+		if !deleted {
+			return n, false
+		}
+		return balance(n.key, n.value, n.left, right), true
+	}
+
+	if n.left == nil {
+		return n.right, true
+	}
+	if n.right == nil {
+		return n.left, true
+	}
+
+	// replace the node with its in-order successor
+	successor, right := removeMin(n.right)
+	return balance(successor.key, successor.value, n.left, right), true
+}
+
+func removeMin[K, V any](n *node[K, V]) (*node[K, V], *node[K, V]) {
+	if n.left == nil {
+		return n, n.right
+	}
+
+	removed, left := removeMin(n.left)
+	return removed, balance(n.key, n.value, left, n.right)
+}
+
+// balance creates a node from the entry and two subtrees whose heights differ by at
+// most two, rotating as necessary to restore the AVL invariant.
+func balance[K, V any](key K, value V, left, right *node[K, V]) *node[K, V] {
+	hl, hr := left.getHeight(), right.getHeight()
+	switch {
+	case hl > hr+1:
+		if left.left.getHeight() >= left.right.getHeight() {
+			return newNode(left.key, left.value, left.left, newNode(key, value, left.right, right))
+		}
+		lr := left.right
+		return newNode(lr.key, lr.value, newNode(left.key, left.value, left.left, lr.left), newNode(key, value, lr.right, right))
+	case hr > hl+1:
+		if right.right.getHeight() >= right.left.getHeight() {
+			return newNode(right.key, right.value, newNode(key, value, left, right.left), right.right)
+		}
+		rl := right.left
+		return newNode(rl.key, rl.value, newNode(key, value, left, rl.left), newNode(right.key, right.value, rl.right, right.right))
+	default:
+		return newNode(key, value, left, right)
+	}
+}
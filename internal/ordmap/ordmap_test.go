@@ -0,0 +1,88 @@
+package ordmap
+
+import (
+	"cmp"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// requireBalanced verifies the AVL invariants of every node in the tree.
+func requireBalanced(t *testing.T, n *node[int, int]) {
+	t.Helper()
+
+	if n == nil {
+		return
+	}
+	require.LessOrEqual(t, n.left.getHeight()-n.right.getHeight(), 1)
+	require.LessOrEqual(t, n.right.getHeight()-n.left.getHeight(), 1)
+	require.Equal(t, max(n.left.getHeight(), n.right.getHeight())+1, n.height)
+	requireBalanced(t, n.left)
+	requireBalanced(t, n.right)
+}
+
+func TestRandomized(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	m := New[int, int](cmp.Compare[int])
+	model := map[int]int{}
+	versions := []Map[int, int]{m}
+	models := []map[int]int{{}}
+
+	for i := 0; i < 2000; i++ {
+		k := r.Intn(200)
+		if r.Intn(3) == 0 {
+			m = m.Delete(k)
+			delete(model, k)
+		} else {
+			m = m.Set(k, i)
+			model[k] = i
+		}
+
+		if i%250 == 0 {
+			snapshot := make(map[int]int, len(model))
+			for k, v := range model {
+				snapshot[k] = v
+			}
+			versions = append(versions, m)
+			models = append(models, snapshot)
+		}
+	}
+	versions = append(versions, m)
+	models = append(models, model)
+
+	for i, v := range versions {
+		requireBalanced(t, v.root)
+		require.Equal(t, len(models[i]), v.Len())
+
+		var expected []int
+		for k := range models[i] {
+			expected = append(expected, k)
+		}
+		sort.Ints(expected)
+
+		var actual []int
+		v.Range(func(k, val int) bool {
+			require.Equal(t, models[i][k], val)
+			actual = append(actual, k)
+			return true
+		})
+		require.Equal(t, expected, actual)
+
+		for k, val := range models[i] {
+			got, ok := v.Get(k)
+			require.True(t, ok)
+			require.Equal(t, val, got)
+		}
+	}
+}
+
+func TestDeleteMissing(t *testing.T) {
+	m := New[string, int](cmp.Compare[string]).Set("a", 1)
+
+	same := m.Delete("b")
+	require.Equal(t, 1, same.Len())
+	require.Same(t, m.root, same.root)
+}
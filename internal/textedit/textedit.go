@@ -0,0 +1,66 @@
+// Package textedit applies the text edits of the suggested fixes reported by the
+// analyzers of this module.
+package textedit
+
+import (
+	"fmt"
+	"go/token"
+	"sort"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// Edit replaces the bytes src[Start:End] of a file with Text.
+type Edit struct {
+	Start, End int
+	Text       string
+}
+
+// ByFile converts the edits to offsets within their files and groups them by file name.
+func ByFile(fset *token.FileSet, edits []analysis.TextEdit) map[string][]Edit {
+	files := make(map[string][]Edit)
+	for _, e := range edits {
+		start, end := fset.Position(e.Pos), fset.Position(e.End)
+		files[start.Filename] = append(files[start.Filename], Edit{start.Offset, end.Offset, string(e.NewText)})
+	}
+	return files
+}
+
+// Apply returns a copy of src with the edits applied. Identical edits are applied once,
+// as fixes of different diagnostics commonly add the same import. Insertions at the
+// same offset are applied in the order of their text. Other overlapping edits conflict
+// and result in an error.
+func Apply(src []byte, edits []Edit) ([]byte, error) {
+	seen := make(map[Edit]bool)
+	unique := make([]Edit, 0, len(edits))
+	for _, e := range edits {
+		if e.Start < 0 || e.Start > e.End || e.End > len(src) {
+			return nil, fmt.Errorf("edit at offsets %d-%d is out of range", e.Start, e.End)
+		}
+		if !seen[e] {
+			seen[e] = true
+			unique = append(unique, e)
+		}
+	}
+	sort.Slice(unique, func(i, j int) bool {
+		if unique[i].Start != unique[j].Start {
+			return unique[i].Start < unique[j].Start
+		}
+		if unique[i].End != unique[j].End {
+			return unique[i].End < unique[j].End
+		}
+		return unique[i].Text < unique[j].Text
+	})
+
+	out := make([]byte, 0, len(src))
+	last := 0
+	for _, e := range unique {
+		if e.Start < last {
+			return nil, fmt.Errorf("conflicting edits at offset %d", e.Start)
+		}
+		out = append(out, src[last:e.Start]...)
+		out = append(out, e.Text...)
+		last = e.End
+	}
+	return append(out, src[last:]...), nil
+}
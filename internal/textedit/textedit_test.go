@@ -0,0 +1,70 @@
+package textedit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestApply(t *testing.T) {
+	type testCase struct {
+		edits    []Edit
+		expected string
+		err      string
+	}
+
+	src := []byte("package p\n\nvar x = f(y)\n")
+
+	cases := map[string]testCase{
+		"no edits": {
+			expected: string(src),
+		},
+		"replacements in any order": {
+			edits: []Edit{
+				{Start: 21, End: 22, Text: "z"},
+				{Start: 19, End: 20, Text: "g"},
+			},
+			expected: "package p\n\nvar x = g(z)\n",
+		},
+		"duplicates applied once": {
+			edits: []Edit{
+				{Start: 11, End: 11, Text: "import \"fmt\"\n"},
+				{Start: 19, End: 20, Text: "g"},
+				{Start: 11, End: 11, Text: "import \"fmt\"\n"},
+			},
+			expected: "package p\n\nimport \"fmt\"\nvar x = g(y)\n",
+		},
+		"insertions at the same offset": {
+			edits: []Edit{
+				{Start: 19, End: 19, Text: "b."},
+				{Start: 19, End: 19, Text: "a."},
+			},
+			expected: "package p\n\nvar x = a.b.f(y)\n",
+		},
+		"overlapping edits": {
+			edits: []Edit{
+				{Start: 19, End: 22, Text: "g(z"},
+				{Start: 21, End: 22, Text: "w"},
+			},
+			err: "conflicting edits at offset 21",
+		},
+		"out of range": {
+			edits: []Edit{{Start: 30, End: 40}},
+			err:   "edit at offsets 30-40 is out of range",
+		},
+	}
+
+	for name, tcase := range cases {
+		tcase := tcase
+
+		t.Run(name, func(t *testing.T) {
+			actual, err := Apply(src, tcase.edits)
+			if tcase.err != "" {
+				require.EqualError(t, err, tcase.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tcase.expected, string(actual))
+		})
+	}
+}